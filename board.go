@@ -0,0 +1,326 @@
+package googs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrOccupied = errors.New("point is occupied")
+	ErrSuicide  = errors.New("suicide is not allowed")
+	ErrKo       = errors.New("ko violation")
+)
+
+// Board is a local Go board to replay moves with captures, e.g. to predict
+// board states before the server confirms. Stones has the same layout as
+// GameState.Board, indexed by [y][x] with value 0=Empty, 1=Black, 2=White.
+type Board struct {
+	Width  int
+	Height int
+	Stones [][]int
+
+	// Same as in Game. When AllowSuperko is false, repeating any earlier
+	// position is rejected according to SuperkoAlgorithm ("psk" for
+	// positional, otherwise situational), otherwise only basic ko is.
+	AllowSelfCapture bool
+	AllowSuperko     bool
+	SuperkoAlgorithm string
+
+	history []string // Positions after every move, starting from empty
+}
+
+// NewBoard creates an empty board with the given dimension.
+func NewBoard(width, height int) *Board {
+	b := &Board{Width: width, Height: height, Stones: make([][]int, height), AllowSuperko: true}
+	for y := range b.Stones {
+		b.Stones[y] = make([]int, width)
+	}
+	b.history = []string{b.position(PlayerBlack)}
+	return b
+}
+
+// NewGameBoard creates an empty board with dimension and rules of the game.
+func NewGameBoard(g *Game) *Board {
+	b := NewBoard(g.Width, g.Height)
+	b.AllowSelfCapture = g.AllowSelfCapture
+	b.AllowSuperko = g.AllowSuperko
+	b.SuperkoAlgorithm = g.SuperkoAlgorithm
+	return b
+}
+
+// ReplayGame replays all moves of the game on a new board.
+func ReplayGame(g *Game) (*Board, error) {
+	b := NewGameBoard(g)
+	for i, m := range g.Moves {
+		if _, err := b.ApplyMove(g.moveColor(i), m.OriginCoordinate); err != nil {
+			return nil, fmt.Errorf("move %d %s: %w", i+1, m.OriginCoordinate, err)
+		}
+	}
+	return b, nil
+}
+
+// moveColor returns the color playing the i-th (zero based) move, the first
+// Handicap moves are placed by Black when handicap is 2 or more.
+func (g *Game) moveColor(i int) PlayerColor {
+	first := cond(g.InitialPlayer == "white", PlayerWhite, PlayerBlack)
+	if g.Handicap > 1 {
+		if i < g.Handicap {
+			return PlayerBlack
+		}
+		first = PlayerWhite
+		i -= g.Handicap
+	}
+	return cond(i%2 == 0, first, opponentColor(first))
+}
+
+func opponentColor(p PlayerColor) PlayerColor {
+	return cond(p == PlayerBlack, PlayerWhite, PlayerBlack)
+}
+
+// ApplyMove plays a stone of the given color, a pass coordinate is accepted
+// without changing the board. Captured stones are returned.
+func (b *Board) ApplyMove(color PlayerColor, c OriginCoordinate) ([]OriginCoordinate, error) {
+	if color != PlayerBlack && color != PlayerWhite {
+		return nil, fmt.Errorf("invalid color %s", color)
+	}
+	if c.IsPass() {
+		b.history = append(b.history, b.position(opponentColor(color)))
+		return nil, nil
+	}
+	if !b.inBounds(c) {
+		return nil, fmt.Errorf("coordinate %s is out of board bounds %d x %d", c, b.Width, b.Height)
+	}
+	if b.Stones[c.Y][c.X] != 0 {
+		return nil, fmt.Errorf("%s: %w", c, ErrOccupied)
+	}
+
+	saved := b.copyStones()
+	b.Stones[c.Y][c.X] = int(color)
+	var captured []OriginCoordinate
+	for _, n := range b.neighbors(c) {
+		if b.Stones[n.Y][n.X] == int(opponentColor(color)) {
+			if group, liberties := b.group(n); liberties == 0 {
+				captured = append(captured, b.remove(group)...)
+			}
+		}
+	}
+	// Basic ko only applies to a move capturing a single stone
+	maybeKo := len(captured) == 1
+	if group, liberties := b.group(c); liberties == 0 {
+		if !b.AllowSelfCapture {
+			b.Stones = saved
+			return nil, fmt.Errorf("%s: %w", c, ErrSuicide)
+		}
+		captured = append(captured, b.remove(group)...)
+	}
+
+	pos := b.position(opponentColor(color))
+	if err := b.checkKo(pos, maybeKo); err != nil {
+		b.Stones = saved
+		return nil, fmt.Errorf("%s: %w", c, err)
+	}
+	b.history = append(b.history, pos)
+	return captured, nil
+}
+
+// ApplyMove advances the state by a move received via OnMove without
+// fetching it again, captured stones are removed and PlayerToMove is switched
+// to the opponent in g, e.g. from Client.Game() or OnGameData. The state is
+// unchanged when an error is returned, e.g. the point is occupied or out of
+// the board. Ko is not checked since earlier positions are unknown.
+func (s *GameState) ApplyMove(g *Game, color PlayerColor, c OriginCoordinate) error {
+	b := &Board{Width: s.BoardSize(), Height: s.BoardSize(), AllowSuperko: true}
+	b.Stones = (&Board{Stones: s.Board}).copyStones()
+	if _, err := b.ApplyMove(color, c); err != nil {
+		return err
+	}
+	s.Board = b.Stones
+	s.MoveNumber++
+	s.LastMove = c
+	s.PlayerToMove = cond(color == PlayerBlack, g.WhitePlayerID, g.BlackPlayerID)
+	return nil
+}
+
+// EstimateScore scores the state by territory with dead stones marked 1 in
+// removal (e.g. GameState.Removal), which may be nil. Dead stones count as
+// prisoners of the opponent and their points as territory, empty regions
+// bordering both colors (e.g. dame or seki) are left unowned. Only prisoners
+// of dead stones are counted since captures during the game are unknown.
+func (s *GameState) EstimateScore(komi float32, removal [][]int) (*Score, error) {
+	size := s.BoardSize()
+	if size == 0 {
+		return nil, fmt.Errorf("invalid empty Board")
+	}
+	if removal != nil && len(removal) != size {
+		return nil, fmt.Errorf("removal has %d rows, want board size %d", len(removal), size)
+	}
+
+	b := &Board{Width: size, Height: size}
+	b.Stones = (&Board{Stones: s.Board}).copyStones()
+	score := &Score{}
+	scores := map[int]*PlayerScore{int(PlayerBlack): &score.Black, int(PlayerWhite): &score.White}
+	for y, row := range b.Stones {
+		if len(row) != size {
+			return nil, fmt.Errorf("board row %d has %d points, want %d", y, len(row), size)
+		}
+		if removal != nil && len(removal[y]) != size {
+			return nil, fmt.Errorf("removal row %d has %d points, want %d", y, len(removal[y]), size)
+		}
+		for x, v := range row {
+			if v != 0 && scores[v] == nil {
+				return nil, fmt.Errorf("invalid stone %d at (%d, %d)", v, x, y)
+			}
+			if v != 0 && removal != nil && removal[y][x] == 1 {
+				scores[int(opponentColor(PlayerColor(v)))].Prisoners++
+				row[x] = 0
+			}
+		}
+	}
+
+	var black, white []string
+	visited := map[OriginCoordinate]bool{}
+	for y, row := range b.Stones {
+		for x, v := range row {
+			if v != 0 {
+				scores[v].Stones++
+				continue
+			}
+			c := OriginCoordinate{x, y}
+			if visited[c] {
+				continue
+			}
+			region, owner := b.region(c, visited)
+			p, ok := scores[owner]
+			if !ok {
+				continue // Neutral
+			}
+			p.Territory += float32(len(region))
+			positions := cond(owner == int(PlayerBlack), &black, &white)
+			for _, r := range region {
+				*positions = append(*positions, r.ToSGF())
+			}
+		}
+	}
+	slices.Sort(black)
+	slices.Sort(white)
+	score.Black.ScoringPositions = strings.Join(black, "")
+	score.White.ScoringPositions = strings.Join(white, "")
+	score.White.Komi = komi
+	score.Black.Total = score.Black.Territory + float32(score.Black.Prisoners)
+	score.White.Total = score.White.Territory + float32(score.White.Prisoners) + komi
+	return score, nil
+}
+
+// region returns the empty points connected to c and the color surrounding
+// them, which is 0 when bordering both colors or none.
+func (b *Board) region(c OriginCoordinate, visited map[OriginCoordinate]bool) ([]OriginCoordinate, int) {
+	visited[c] = true
+	stack := []OriginCoordinate{c}
+	var region []OriginCoordinate
+	owner, neutral := 0, false
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		region = append(region, cur)
+		for _, n := range b.neighbors(cur) {
+			switch v := b.Stones[n.Y][n.X]; {
+			case v != 0 && owner == 0:
+				owner = v
+			case v != 0 && v != owner:
+				neutral = true
+			case v == 0 && !visited[n]:
+				visited[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return region, cond(neutral, 0, owner)
+}
+
+func (b *Board) checkKo(pos string, maybeKo bool) error {
+	n := len(b.history)
+	if maybeKo && n >= 2 && samePosition(b.history[n-2], pos) {
+		return ErrKo
+	}
+	if b.AllowSuperko {
+		return nil
+	}
+	for _, p := range b.history {
+		if p == pos || (b.SuperkoAlgorithm == "psk" && samePosition(p, pos)) {
+			return fmt.Errorf("superko: %w", ErrKo)
+		}
+	}
+	return nil
+}
+
+// position encodes stones followed by the color to play next.
+func (b *Board) position(next PlayerColor) string {
+	var sb strings.Builder
+	for _, row := range b.Stones {
+		for _, v := range row {
+			sb.WriteByte(byte('0' + v))
+		}
+	}
+	sb.WriteByte(byte('0' + next))
+	return sb.String()
+}
+
+// samePosition compares stones only, ignoring the color to play.
+func samePosition(a, b string) bool {
+	return a[:len(a)-1] == b[:len(b)-1]
+}
+
+func (b *Board) inBounds(c OriginCoordinate) bool {
+	return c.X >= 0 && c.X < b.Width && c.Y >= 0 && c.Y < b.Height
+}
+
+func (b *Board) neighbors(c OriginCoordinate) []OriginCoordinate {
+	var res []OriginCoordinate
+	for _, n := range []OriginCoordinate{{c.X - 1, c.Y}, {c.X + 1, c.Y}, {c.X, c.Y - 1}, {c.X, c.Y + 1}} {
+		if b.inBounds(n) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// group returns the stones connected to c and the number of their liberties.
+func (b *Board) group(c OriginCoordinate) ([]OriginCoordinate, int) {
+	color := b.Stones[c.Y][c.X]
+	visited := map[OriginCoordinate]bool{c: true}
+	liberties := map[OriginCoordinate]bool{}
+	stack := []OriginCoordinate{c}
+	var group []OriginCoordinate
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		group = append(group, cur)
+		for _, n := range b.neighbors(cur) {
+			switch v := b.Stones[n.Y][n.X]; {
+			case v == 0:
+				liberties[n] = true
+			case v == color && !visited[n]:
+				visited[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return group, len(liberties)
+}
+
+func (b *Board) remove(stones []OriginCoordinate) []OriginCoordinate {
+	for _, s := range stones {
+		b.Stones[s.Y][s.X] = 0
+	}
+	return stones
+}
+
+func (b *Board) copyStones() [][]int {
+	res := make([][]int, len(b.Stones))
+	for y, row := range b.Stones {
+		res[y] = append([]int(nil), row...)
+	}
+	return res
+}
@@ -0,0 +1,199 @@
+package googs
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// play applies alternating moves starting with Black.
+func play(t *testing.T, b *Board, moves ...OriginCoordinate) {
+	t.Helper()
+	for i, m := range moves {
+		if _, err := b.ApplyMove(cond(i%2 == 0, PlayerBlack, PlayerWhite), m); err != nil {
+			t.Fatalf("ApplyMove(%s) want no error, got %v", m, err)
+		}
+	}
+}
+
+var pass = OriginCoordinate{X: -1, Y: -1}
+
+func TestBoard_ApplyMove_Capture(t *testing.T) {
+	b := NewBoard(9, 9)
+	// White stone at (1,0) surrounded by black stones at (0,0), (2,0), (1,1)
+	play(t, b, OriginCoordinate{0, 0}, OriginCoordinate{1, 0}, OriginCoordinate{2, 0}, pass)
+
+	captured, err := b.ApplyMove(PlayerBlack, OriginCoordinate{1, 1})
+	if err != nil {
+		t.Fatalf("ApplyMove() want no error, got %v", err)
+	}
+	if want := []OriginCoordinate{{1, 0}}; !reflect.DeepEqual(captured, want) {
+		t.Errorf("ApplyMove() want captured %v, got %v", want, captured)
+	}
+	if b.Stones[0][1] != 0 {
+		t.Errorf("captured stone want removed, got %d", b.Stones[0][1])
+	}
+
+	if _, err := b.ApplyMove(PlayerWhite, OriginCoordinate{0, 0}); !errors.Is(err, ErrOccupied) {
+		t.Errorf("ApplyMove() on occupied point want %v, got %v", ErrOccupied, err)
+	}
+	if _, err := b.ApplyMove(PlayerWhite, OriginCoordinate{9, 0}); err == nil {
+		t.Errorf("ApplyMove() out of bounds want error, got nil")
+	}
+}
+
+func TestBoard_ApplyMove_Ko(t *testing.T) {
+	b := NewBoard(9, 9)
+	// Ko shape around (1,1) and (2,1):
+	//   . B W .
+	//   B W . W
+	//   . B W .
+	play(t, b,
+		OriginCoordinate{1, 0}, OriginCoordinate{2, 0},
+		OriginCoordinate{0, 1}, OriginCoordinate{3, 1},
+		OriginCoordinate{1, 2}, OriginCoordinate{2, 2},
+		pass, OriginCoordinate{1, 1},
+	)
+
+	captured, err := b.ApplyMove(PlayerBlack, OriginCoordinate{2, 1})
+	if err != nil || len(captured) != 1 {
+		t.Fatalf("ApplyMove() taking ko want 1 capture, got %v, %v", captured, err)
+	}
+	if _, err := b.ApplyMove(PlayerWhite, OriginCoordinate{1, 1}); !errors.Is(err, ErrKo) {
+		t.Errorf("ApplyMove() retaking ko want %v, got %v", ErrKo, err)
+	}
+	if b.Stones[1][2] != int(PlayerBlack) || b.Stones[1][1] != 0 {
+		t.Errorf("board want unchanged after illegal move, got %v", b.Stones[1])
+	}
+
+	// Retake after a ko threat exchange
+	play(t, b, OriginCoordinate{8, 8}, OriginCoordinate{7, 8})
+	b.ApplyMove(PlayerWhite, pass)
+	if _, err := b.ApplyMove(PlayerWhite, OriginCoordinate{1, 1}); err != nil {
+		t.Errorf("ApplyMove() retaking ko later want no error, got %v", err)
+	}
+}
+
+func TestBoard_ApplyMove_SelfCapture(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		b := NewBoard(9, 9)
+		b.AllowSelfCapture = allow
+		play(t, b, OriginCoordinate{1, 0}, pass, OriginCoordinate{0, 1}, pass)
+
+		captured, err := b.ApplyMove(PlayerWhite, OriginCoordinate{0, 0})
+		if !allow {
+			if !errors.Is(err, ErrSuicide) || b.Stones[0][0] != 0 {
+				t.Errorf("ApplyMove() suicide want %v, got %v", ErrSuicide, err)
+			}
+			continue
+		}
+		if err != nil || len(captured) != 1 || b.Stones[0][0] != 0 {
+			t.Errorf("ApplyMove() self capture want own stone removed, got %v, %v", captured, err)
+		}
+	}
+}
+
+func TestReplayGame(t *testing.T) {
+	g := &Game{
+		Width:    9,
+		Height:   9,
+		Handicap: 2,
+		Moves: []Move{
+			{OriginCoordinate: OriginCoordinate{2, 2}},
+			{OriginCoordinate: OriginCoordinate{6, 6}},
+			{OriginCoordinate: OriginCoordinate{4, 4}},
+		},
+	}
+	b, err := ReplayGame(g)
+	if err != nil {
+		t.Fatalf("ReplayGame() want no error, got %v", err)
+	}
+	if b.Stones[2][2] != 1 || b.Stones[6][6] != 1 || b.Stones[4][4] != 2 {
+		t.Errorf("ReplayGame() got unexpected board %v", b.Stones)
+	}
+}
+
+func TestGameState_ApplyMove(t *testing.T) {
+	var s GameState
+	data := `{"phase": "play", "move_number": 3, "player_to_move": 7, "board": [[1, 2, 0], [0, 1, 0], [0, 0, 0]]}`
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatalf("Unmarshal() want no error, got %v", err)
+	}
+	g := &Game{BlackPlayerID: 7, WhitePlayerID: 8}
+	if err := s.ApplyMove(g, PlayerBlack, OriginCoordinate{2, 0}); err != nil {
+		t.Fatalf("ApplyMove() want no error, got %v", err)
+	}
+	want := [][]int{{1, 0, 1}, {0, 1, 0}, {0, 0, 0}}
+	if !reflect.DeepEqual(s.Board, want) || s.MoveNumber != 4 || s.LastMove != (OriginCoordinate{2, 0}) || s.PlayerToMove != 8 {
+		t.Errorf("ApplyMove() got unexpected state %+v", s)
+	}
+
+	if err := s.ApplyMove(g, PlayerWhite, pass); err != nil || s.MoveNumber != 5 || s.PlayerToMove != 7 || !s.LastMove.IsPass() {
+		t.Errorf("ApplyMove() with pass got unexpected state %+v, %v", s, err)
+	}
+
+	for _, c := range []OriginCoordinate{{0, 0}, {3, 0}, {0, -2}} {
+		if err := s.ApplyMove(g, PlayerBlack, c); err == nil {
+			t.Errorf("ApplyMove(%s) want error, got nil", c)
+		}
+	}
+	if !reflect.DeepEqual(s.Board, want) || s.MoveNumber != 5 || s.PlayerToMove != 7 {
+		t.Errorf("ApplyMove() with invalid moves want state unchanged, got %+v", s)
+	}
+}
+
+func TestGameState_EstimateScore(t *testing.T) {
+	s := &GameState{Board: [][]int{
+		{0, 1, 2, 0, 0},
+		{1, 1, 2, 0, 2},
+		{0, 1, 2, 0, 0},
+		{1, 1, 2, 2, 2},
+		{0, 1, 0, 1, 0}, // Black (3,4) is dead, the bottom right region is dame
+	}}
+	removal := make([][]int, 5)
+	for y := range removal {
+		removal[y] = make([]int, 5)
+	}
+	removal[4][3] = 1
+
+	got, err := s.EstimateScore(6.5, removal)
+	if err != nil {
+		t.Fatalf("EstimateScore() want no error, got %v", err)
+	}
+	want := &Score{
+		Black: PlayerScore{Stones: 7, Territory: 3, Total: 3, ScoringPositions: "aaacae"},
+		White: PlayerScore{Stones: 7, Territory: 5, Prisoners: 1, Komi: 6.5, Total: 12.5, ScoringPositions: "dadbdceaec"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EstimateScore() want %+v, got %+v", want, got)
+	}
+
+	// Neutral point between both colors
+	s = &GameState{Board: [][]int{{1, 0, 2}, {1, 0, 2}, {1, 0, 2}}}
+	if got, err := s.EstimateScore(0, nil); err != nil || got.Black.Territory != 0 || got.White.Territory != 0 {
+		t.Errorf("EstimateScore() with dame want no territory, got %+v, %v", got, err)
+	}
+	if _, err := s.EstimateScore(0, [][]int{{0}}); err == nil {
+		t.Errorf("EstimateScore() with mismatched removal want error, got nil")
+	}
+}
+
+func TestGameState_EstimateScore_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		board   [][]int
+		removal [][]int
+	}{
+		{"empty removal", [][]int{{0, 1}, {2, 0}}, [][]int{}},
+		{"ragged removal", [][]int{{0, 1}, {2, 0}}, [][]int{{0, 0}, {0}}},
+		{"ragged board", [][]int{{0, 1}, {2}}, nil},
+		{"bad stone", [][]int{{0, 1}, {3, 0}}, nil},
+		{"negative stone", [][]int{{0, -1}, {2, 0}}, [][]int{{0, 1}, {0, 0}}},
+	} {
+		s := &GameState{Board: tc.board}
+		if got, err := s.EstimateScore(0, tc.removal); err == nil {
+			t.Errorf("EstimateScore() with %s want error, got %+v", tc.name, got)
+		}
+	}
+}
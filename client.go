@@ -2,12 +2,14 @@ package googs
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"net/url"
-	"os"
+	"sync"
 	"time"
-
-	socketio "github.com/graarh/golang-socketio"
 )
 
 // Token represents an OAuth-compatible token structure.
@@ -37,17 +39,88 @@ type Client struct {
 	Username string `json:"-"`
 	UserID   int64  `json:"-"`
 
+	// HTTPClient is used for all REST requests including authentication,
+	// a shared client with 30s timeout is used when nil.
+	HTTPClient *http.Client `json:"-"`
+
+	// AutoRefresh enables refreshing the access token once and retrying when
+	// a REST request is rejected with 401. Defaults to true for clients
+	// created via NewClient or LoadClient.
+	AutoRefresh bool `json:"-"`
+
+	// MaxRetries is the number of times a REST request is retried when
+	// OGS responds 429 or 503, no retry by default. A 429 is retried once
+	// regardless when SetRateLimit is in effect. Responses asking to retry
+	// after more than a minute are returned without retrying.
+	MaxRetries int `json:"-"`
+
 	// Internal
-	socket *socketio.Client
+	baseURL              string       // ogsBaseURL when empty
+	realtimeURL          string       // realtimeURL when empty
+	logger               *slog.Logger // Nothing is logged when nil
+	tokenMu              sync.Mutex   // Guards Token
+	refreshMu            sync.Mutex   // Serializes transparent token refreshes
+	onCredentialsUpdated func(*Client)
+	store                CredentialStore // Set by LoadClient to persist refreshed credentials
+	limiter              *rateLimiter    // No rate limit when nil
+	mu                   sync.Mutex
+	socket               socket
+	dial                 func() (socket, error) // Dials realtimeURL when nil
+	closed               bool                   // Disconnect() was called
+	handlers             map[string][]*handler  // Restored on reconnect
+	games                map[int64]map[string]any
+	buffered             map[string]json.RawMessage // Latest payload of events kept by buffer()
+	lastMoves            map[int64]int              // Last move number of connected games
+	seekGraph            bool                       // SeekGraphConnect() was called
+	drift                time.Duration              // Local minus server clock, measured by Ping()
+	latency              time.Duration              // Round trip, measured by Ping()
+	latencyInterval      time.Duration              // No latency report when zero
+	reportingLatency     bool                       // The reporter goroutine is running
+	keepalive            chan struct{}              // Closed to stop StartKeepalive()
+	maxAttempts          int
+	baseDelay            time.Duration
+	maxDelay             time.Duration
+	onReconnect          func(attempt int)
+	onConnState          func(ConnState)
+	reconnecting         bool
 }
 
 // NewClient creates a Client instance with the given client ID and secret,
 // Login() should be called for authentication.
-func NewClient(clientID, clientSecret string) *Client {
-	return &Client{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
+func NewClient(clientID, clientSecret string, opts ...ClientOption) *Client {
+	c := newClient(opts...)
+	c.ClientID = clientID
+	c.ClientSecret = clientSecret
+	return c
+}
+
+// newClient creates a Client with default settings overridden by opts. By
+// default expired tokens are refreshed and a dropped websocket is reconnected
+// forever with backoff from 1s up to 1m.
+func newClient(opts ...ClientOption) *Client {
+	c := &Client{
+		AutoRefresh: true,
+		maxAttempts: -1,
+		baseDelay:   time.Second,
+		maxDelay:    time.Minute,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// NewAnonymousClient creates a Client without credentials and establishes
+// websocket connection to OGS, it can fetch public REST resources (e.g. Game,
+// GameState, PlayerByID) and watch games via GameConnect. Methods requiring
+// authentication return ErrAuthRequired.
+func NewAnonymousClient(opts ...ClientOption) (*Client, error) {
+	c := newClient(opts...)
+	c.AutoRefresh = false // Nothing to refresh
+	if err := c.connect(); err != nil {
+		return c, err
+	}
+	return c, nil
 }
 
 // Login authenticates the Client with the given username and password, also
@@ -80,51 +153,106 @@ func (c *Client) LoggedIn() bool {
 	return c != nil && c.AccessToken != "" && c.Username != "" && c.socket != nil
 }
 
+// Logout revokes the access and refresh tokens, clears credentials and closes
+// the websocket connection. Credentials are cleared even if revoking failed,
+// so a subsequent Save() doesn't write them.
+func (c *Client) Logout() error {
+	c.tokenMu.Lock()
+	token := c.Token
+	c.Token = Token{}
+	c.tokenMu.Unlock()
+	c.Auth = Auth{}
+	c.Username = ""
+	c.UserID = 0
+	c.Disconnect()
+
+	var errs []error
+	for _, t := range []struct{ hint, value string }{
+		{"access_token", token.AccessToken},
+		{"refresh_token", token.RefreshToken},
+	} {
+		if t.value == "" {
+			continue
+		}
+		data := url.Values{}
+		data.Set("token", t.value)
+		data.Set("token_type_hint", t.hint)
+		data.Set("client_id", c.ClientID)
+		data.Set("client_secret", c.ClientSecret)
+		if _, err := c.ogsPost("/oauth2/revoke_token/", data); err != nil {
+			errs = append(errs, fmt.Errorf("failed to revoke %s: %w", t.hint, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Save stores authenticated Client credentials into a file in JSON format.
 // This is recommended practice right after logged in via Login() once.
 func (c *Client) Save(secretFile string) error {
-	data, err := json.MarshalIndent(c, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(secretFile, data, 0600)
+	return c.SaveTo(FileStore(secretFile))
 }
 
 // Load stores Client credentials from a JSON file previously written via
 // Save(),  also establishes websocket connection to OGS so the Client is ready
 // to use right after. Caller should always check error first, because an
 // incomplete client may be returned for caller to access available information
-// (e.g. to prefill Client ID in a login form).
-func LoadClient(secretFile string) (*Client, error) {
-	data, err := os.ReadFile(secretFile)
+// (e.g. to prefill Client ID in a login form). Credentials refreshed
+// transparently later on are saved back to secretFile.
+func LoadClient(secretFile string, opts ...ClientOption) (*Client, error) {
+	return LoadClientFrom(FileStore(secretFile), opts...)
+}
+
+// LoadClientFrom is like LoadClient but loads credentials from the store,
+// refreshed credentials are saved back to the same store.
+func LoadClientFrom(store CredentialStore, opts ...ClientOption) (*Client, error) {
+	c := newClient(opts...)
+	c.store = store
+	data, err := store.Load()
 	if err != nil {
-		return &Client{}, err
+		return c, err
 	}
-	var c Client
-	if err := json.Unmarshal(data, &c); err != nil {
-		return &c, err
+	return c, c.restore(data)
+}
+
+// ReadClient is like LoadClient but reads credentials written via WriteTo()
+// from r. Refreshed credentials are not written back anywhere, register
+// OnCredentialsUpdated to persist them.
+func ReadClient(r io.Reader, opts ...ClientOption) (*Client, error) {
+	c := newClient(opts...)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return c, err
+	}
+	return c, c.restore(data)
+}
+
+// restore decodes persisted credentials, refreshes them on demand and
+// establishes websocket connection.
+func (c *Client) restore(data []byte) error {
+	if err := c.unmarshal(data); err != nil {
+		return err
 	}
 
 	// OGS access token is valid for 30 days, refresh if it's expiring in
 	// 7 days.
 	refreshed, err := c.MaybeRefresh(time.Hour * 24 * 7)
 	if err != nil {
-		return &c, err
+		return err
 	}
-	if refreshed {
-		if err := c.Save(secretFile); err != nil {
-			return &c, err
+	if refreshed && c.store != nil {
+		if err := c.SaveTo(c.store); err != nil {
+			return err
 		}
 	}
 
 	if err := c.Identify(); err != nil {
-		return &c, err
+		return err
 	}
 
 	if err := c.connect(); err != nil {
-		return &c, err
+		return err
 	}
-	return &c, nil
+	return nil
 }
 
 // Identify verifies Client access token and populate Username & UserID fields.
@@ -156,19 +284,25 @@ func (c *Client) refreshToken() error {
 
 func (c *Client) authenticate(data url.Values) error {
 	// Request tokens
-	body, err := ogsPost("/oauth2/token/", data)
+	body, err := c.ogsPost("/oauth2/token/", data)
 	if err != nil {
 		return fmt.Errorf("failed to request token: %w", err)
 	}
-	if err := json.Unmarshal(body, &c.Token); err != nil {
+	c.tokenMu.Lock()
+	token := c.Token
+	c.tokenMu.Unlock()
+	if err := json.Unmarshal(body, &token); err != nil {
 		return err
 	}
 
-	c.ExpiresAt = time.Now().Add(time.Duration(c.ExpiresIn) * time.Second)
-	c.ExpiresIn = 0 // Unset to omit when persisting to file
+	token.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	token.ExpiresIn = 0 // Unset to omit when persisting to file
+	c.tokenMu.Lock()
+	c.Token = token
+	c.tokenMu.Unlock()
 
-	// Request auth config
-	if err := c.Get("/api/v1/ui/config/", nil, &c.Auth); err != nil {
+	// Request auth config, never retry to avoid refreshing recursively
+	if err := c.get("/api/v1/ui/config/", nil, &c.Auth); err != nil {
 		return fmt.Errorf("failed to request auth config: %w", err)
 	}
 
@@ -177,12 +311,66 @@ func (c *Client) authenticate(data url.Values) error {
 
 // MaybeRefresh validates the expiry of Client credentials and refresh
 // credentials on demand, a true value is returned when refresh happened
-// successfully. Save() is expected to persist the new credentials.
+// successfully. Save() is expected to persist the new credentials. Only an
+// authentication failure (401 or 403) triggers refresh, other errors like a
+// flaky network are returned as is.
 func (c *Client) MaybeRefresh(deadline time.Duration) (bool, error) {
 	expiring := time.Now().Add(deadline).After(c.ExpiresAt)
-	if expiring || c.Identify() != nil {
-		err := c.refreshToken()
-		return err == nil, err
+	if !expiring {
+		stale := c.accessToken()
+		err := c.Identify()
+		// Identify() might have refreshed transparently
+		refreshed := c.accessToken() != stale
+		if err == nil || refreshed || !isAuthError(err) {
+			return refreshed, err
+		}
+	}
+	err := c.refreshToken()
+	return err == nil, err
+}
+
+// OnCredentialsUpdated registers a callback invoked after credentials are
+// refreshed transparently by a REST request, e.g. to persist them via Save().
+// Clients created by LoadClient save to the same store automatically.
+func (c *Client) OnCredentialsUpdated(fn func(*Client)) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	c.onCredentialsUpdated = fn
+}
+
+// refreshUnauthorized refreshes the token after a request using the stale
+// access token was rejected. Concurrent callers wait for a single refresh.
+func (c *Client) refreshUnauthorized(stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if c.accessToken() != stale {
+		return nil // Refreshed by another goroutine
 	}
-	return false, nil
+	if err := c.refreshToken(); err != nil {
+		return err
+	}
+	if c.store != nil {
+		// The request can proceed with the new token regardless
+		if err := c.SaveTo(c.store); err != nil {
+			c.warn("Failed to save refreshed credentials", "error", err)
+		}
+	}
+	if c.onCredentialsUpdated != nil {
+		c.onCredentialsUpdated(c)
+	}
+	return nil
+}
+
+// requireAuth returns ErrAuthRequired for an anonymous Client.
+func (c *Client) requireAuth() error {
+	if c.accessToken() == "" {
+		return ErrAuthRequired
+	}
+	return nil
+}
+
+func (c *Client) accessToken() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.AccessToken
 }
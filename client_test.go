@@ -0,0 +1,317 @@
+package googs
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_MaybeRefresh(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		meStatus      int
+		wantRefreshed bool
+		wantStatus    int // Of the returned *APIError, 0 for no error
+	}{
+		{
+			name:          "valid token",
+			meStatus:      http.StatusOK,
+			wantRefreshed: false,
+		},
+		{
+			name:          "unauthorized",
+			meStatus:      http.StatusUnauthorized,
+			wantRefreshed: true,
+		},
+		{
+			name:          "forbidden",
+			meStatus:      http.StatusForbidden,
+			wantRefreshed: true,
+		},
+		{
+			name:          "server error",
+			meStatus:      http.StatusInternalServerError,
+			wantRefreshed: false,
+			wantStatus:    http.StatusInternalServerError,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			refreshes := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+				if tc.meStatus != http.StatusOK && r.Header.Get("Authorization") != "Bearer new" {
+					w.WriteHeader(tc.meStatus)
+					return
+				}
+				writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+			})
+			mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+				refreshes++
+				writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+			})
+			mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, map[string]any{})
+			})
+			c := newTestClient(t, mux)
+			c.RefreshToken = "old"
+			c.ExpiresAt = time.Now().Add(30 * 24 * time.Hour)
+
+			refreshed, err := c.MaybeRefresh(7 * 24 * time.Hour)
+			if refreshed != tc.wantRefreshed || refreshes != cond(tc.wantRefreshed, 1, 0) {
+				t.Errorf("MaybeRefresh() want refreshed %v, got %v (%d refreshes)", tc.wantRefreshed, refreshed, refreshes)
+			}
+			var apiErr *APIError
+			if tc.wantStatus == 0 && err != nil {
+				t.Errorf("MaybeRefresh() want no error, got %v", err)
+			}
+			if tc.wantStatus != 0 && (!errors.As(err, &apiErr) || apiErr.StatusCode != tc.wantStatus) {
+				t.Errorf("MaybeRefresh() want APIError %d, got %v", tc.wantStatus, err)
+			}
+		})
+	}
+}
+
+func TestClient_MaybeRefresh_Expiring(t *testing.T) {
+	refreshes := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{})
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "old"
+	c.ExpiresAt = time.Now().Add(24 * time.Hour)
+
+	refreshed, err := c.MaybeRefresh(7 * 24 * time.Hour)
+	if !refreshed || err != nil || refreshes != 1 {
+		t.Errorf("MaybeRefresh() want refreshed, got %v, %v (%d refreshes)", refreshed, err, refreshes)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewClient_Options(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	transport := &countingTransport{}
+	c := NewClient("id", "secret",
+		WithBaseURL(server.URL),
+		WithHTTPTimeout(5*time.Second),
+		WithHTTPTransport(transport),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	c.AccessToken = "token"
+	if c.HTTPClient == nil || c.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("WithHTTPTimeout() want 5s timeout, got %+v", c.HTTPClient)
+	}
+	if defaultHTTPClient.Timeout != 30*time.Second || defaultHTTPClient.Transport != nil {
+		t.Errorf("options must not modify the default http.Client, got %+v", defaultHTTPClient)
+	}
+
+	if err := c.Identify(); err != nil {
+		t.Fatalf("Identify() want no error, got %v", err)
+	}
+	if c.Username != "alice" {
+		t.Errorf("Identify() want alice, got %q", c.Username)
+	}
+	if transport.requests != 1 {
+		t.Errorf("WithHTTPTransport() want 1 request, got %d", transport.requests)
+	}
+	if !strings.Contains(logs.String(), "uri=/api/v1/me") {
+		t.Errorf("WithLogger() want request logged, got %q", logs.String())
+	}
+}
+
+func TestClient_Get_RefreshOnUnauthorized(t *testing.T) {
+	var mu sync.Mutex
+	refreshes, updates := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+	})
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		refreshes++
+		mu.Unlock()
+		writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{})
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "old"
+	c.OnCredentialsUpdated(func(*Client) { updates++ })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.AboutMe(); err != nil {
+				t.Errorf("AboutMe() want no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if refreshes != 1 || updates != 1 {
+		t.Errorf("want single refresh and update, got %d refreshes, %d updates", refreshes, updates)
+	}
+}
+
+func TestClient_Get_RefreshFailed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "revoked"
+
+	_, err := c.AboutMe()
+	if statusCode(err) != http.StatusUnauthorized {
+		t.Errorf("AboutMe() want APIError 401, got %v", err)
+	}
+}
+
+func TestClient_Get_AutoRefresh(t *testing.T) {
+	refreshes := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+	})
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"user_jwt": "jwt"})
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "old"
+
+	c.AutoRefresh = false
+	if _, err := c.AboutMe(); statusCode(err) != http.StatusUnauthorized || refreshes != 0 {
+		t.Errorf("AboutMe() without AutoRefresh want APIError 401 and no refresh, got %v (%d refreshes)", err, refreshes)
+	}
+
+	c.AutoRefresh = true
+	secretFile := filepath.Join(t.TempDir(), "secret.json")
+	c.store = FileStore(secretFile)
+	if _, err := c.AboutMe(); err != nil || refreshes != 1 {
+		t.Fatalf("AboutMe() want no error after 1 refresh, got %v (%d refreshes)", err, refreshes)
+	}
+	data, err := os.ReadFile(secretFile)
+	if err != nil {
+		t.Fatalf("refreshed credentials want saved, got %v", err)
+	}
+	if !strings.Contains(string(data), `"access_token": "new"`) || !strings.Contains(string(data), `"user_jwt": "jwt"`) {
+		t.Errorf("saved credentials want new token, got %s", data)
+	}
+}
+
+func TestNewClient_ServerOverride(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/oauth2/token/":
+			writeJSON(w, map[string]any{"access_token": "token", "refresh_token": "refresh", "expires_in": 3600})
+		case "/api/v1/ui/config/":
+			writeJSON(w, map[string]any{"user_jwt": "jwt"})
+		case "/api/v1/me":
+			writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+		case "/api/v1/games/1":
+			writeJSON(w, map[string]any{"gamedata": map[string]any{"game_id": 1, "width": 9, "height": 9}})
+		default:
+			w.WriteHeader(http.StatusNotFound) // Fails the websocket upgrade
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("id", "secret",
+		WithBaseURL(server.URL),
+		WithRealtimeURL("ws"+strings.TrimPrefix(server.URL, "http")+"/socket.io/?transport=websocket&EIO=3"),
+	)
+	if err := c.Login("alice", "password"); err == nil {
+		t.Errorf("Login() want websocket error from the fake server, got nil")
+	}
+	g, err := c.Game(1)
+	if err != nil {
+		t.Fatalf("Game() want no error, got %v", err)
+	}
+	if want := server.URL + "/game/1"; g.URL() != want {
+		t.Errorf("Game.URL() want %q, got %q", want, g.URL())
+	}
+
+	want := []string{"/oauth2/token/", "/api/v1/ui/config/", "/api/v1/me", "/socket.io/", "/api/v1/games/1"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("requests to the fake server want %v, got %v", want, paths)
+	}
+}
+
+func TestClient_Logout(t *testing.T) {
+	var revoked []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/revoke_token/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		revoked = append(revoked, r.PostForm.Get("token_type_hint")+"="+r.PostForm.Get("token"))
+		if r.PostForm.Get("token_type_hint") == "refresh_token" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "refresh"
+	c.UserJWT = "jwt"
+	c.Username, c.UserID = "alice", 1
+	sock := &fakeSocket{}
+	c.socket = sock
+
+	err := c.Logout()
+	if statusCode(err) != http.StatusBadRequest {
+		t.Errorf("Logout() want APIError 400 revoking refresh token, got %v", err)
+	}
+	if want := []string{"access_token=token", "refresh_token=refresh"}; !reflect.DeepEqual(revoked, want) {
+		t.Errorf("Logout() want revoked %v, got %v", want, revoked)
+	}
+	if c.Token != (Token{}) || c.Auth != (Auth{}) || c.Username != "" || c.UserID != 0 || !sock.closed {
+		t.Errorf("Logout() want credentials cleared and disconnected, got %+v", c)
+	}
+}
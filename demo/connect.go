@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ymattw/googs"
@@ -21,7 +24,14 @@ func connect(args ...string) {
 		log.Fatal(err)
 	}
 
-	client := loadClient()
+	client, err := googs.LoadClient(*secretFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("No %s found, connecting anonymously to watch only", *secretFile)
+		client, err = googs.NewAnonymousClient()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Fetch current game information once
 	game, err := client.Game(gameID)
@@ -35,7 +45,7 @@ func connect(args ...string) {
 	defer close(chGame)
 	defer close(chGameMove)
 
-	if err := client.GameConnect(gameID); err != nil {
+	if err := client.GameConnectAck(gameID, 10*time.Second); err != nil {
 		log.Fatal(err)
 	}
 	defer client.GameDisconnect(gameID)
@@ -50,11 +60,35 @@ func connect(args ...string) {
 		log.Printf("Not your game, watching only")
 	}
 
+	// Latest clocks, shown along with the board
+	clocks, stopClock, err := client.WatchClock(gameID, time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer stopClock()
+	var clock atomic.Pointer[googs.ComputedClockPair]
+	go func() {
+		for pair := range clocks {
+			clock.Store(pair)
+		}
+	}()
+
 	client.OnMove(gameID, func(m *googs.GameMove) {
 		// log.Printf("Sending submitted move %v", m)
 		chGameMove <- m
 	})
 
+	// Move number of pending undo request from the opponent, 0 for none
+	var undoMove atomic.Int64
+	client.OnUndoRequested(gameID, func(moveNumber int) {
+		log.Printf(`Opponent requests to undo move %d, enter "undo" to accept`, moveNumber)
+		undoMove.Store(int64(moveNumber))
+	})
+	client.OnUndoAccepted(gameID, func(moveNumber int) {
+		log.Printf("Move %d was undone", moveNumber)
+		undoMove.Store(0)
+	})
+
 	// NOTE: `gameState` is updated on every move, `game` is only updated
 	// on game result change.
 	var gameState *googs.GameState
@@ -71,24 +105,20 @@ func connect(args ...string) {
 			numMoves = gameState.MoveNumber
 			drawBoard(gameState)
 			log.Printf("%s", game.Status(gameState, client.UserID))
+			if pair := clock.Load(); pair != nil {
+				log.Printf("%s %s, %s %s", game.BlackPlayerTitle(), pair.Black, game.WhitePlayerTitle(), pair.White)
+			}
 		}
-		if gameState.Phase == "finished" {
+		if gameState.Phase.IsTerminal() {
 			log.Printf("%s", game.Result())
 			break
 		}
 
 		if gameState.IsMyTurn(client.UserID) {
-			for {
-				if err := playMove(client, gameID, game.BoardSize()); err != nil {
-					log.Printf("Failed to submit move: %v", err)
-				}
-				break
-			}
-			select {
-			case <-chGameMove:
-			case game = <-chGame:
-			case <-time.After(500 * time.Millisecond):
-				log.Printf("Last move wasn't submitted, illegal move? Try again")
+			// The move echo is left in chGameMove and consumed by
+			// the next loop
+			if err := playMove(client, gameID, gameState, int(undoMove.Swap(0))); err != nil {
+				log.Printf("Failed to submit move: %v, try again", err)
 			}
 		} else { // blocking
 			select {
@@ -99,8 +129,10 @@ func connect(args ...string) {
 	}
 }
 
-func playMove(client *googs.Client, gameID int64, boardSize int) error {
-	log.Printf(`Your turn. Enter a coordinate in "A1" format, "pass" or "resign"`)
+const moveTimeout = 5 * time.Second
+
+func playMove(client *googs.Client, gameID int64, gameState *googs.GameState, undoMove int) error {
+	log.Printf(`Your turn. Enter a coordinate in "A1" format, "pass", "resign" or "cancel"`)
 	fmt.Print("> ")
 	reader := bufio.NewReader(os.Stdin)
 	op, _ := reader.ReadString('\n')
@@ -108,19 +140,26 @@ func playMove(client *googs.Client, gameID int64, boardSize int) error {
 
 	switch op {
 	case "PASS":
-		return client.PassTurn(gameID)
+		return client.GameMoveAck(gameID, -1, -1, moveTimeout)
 	case "RESIGN":
 		return client.GameResign(gameID)
+	case "CANCEL":
+		return client.GameCancel(gameID, gameState)
+	case "UNDO":
+		if undoMove == 0 {
+			return fmt.Errorf("no undo requested")
+		}
+		return client.AcceptUndo(gameID, undoMove)
 	default:
 		a1, err := googs.NewA1Coordinate(op)
 		if err != nil {
 			return err
 		}
-		coord, err := a1.ToOriginCoordinate(boardSize)
+		coord, err := a1.ToOriginCoordinate(gameState.BoardSize())
 		if err != nil {
 			return err
 		}
-		return client.GameMove(gameID, coord.X, coord.Y)
+		return client.GameMoveAck(gameID, coord.X, coord.Y, moveTimeout)
 	}
 }
 
@@ -25,6 +25,8 @@ const usage = `Typical usage:
 
   go run ./demo overview                # show my active games
   go run ./demo connect 123             # connect to a game to watch or play
+                                        # watch only without secret.json
+  go run ./demo player alice            # show user profile by ID or username
   go run ./demo rest /api/v1/players/1  # debug rest API (shows user profile)
 `
 
@@ -44,6 +46,8 @@ func main() {
 		overview()
 	case "connect":
 		connect(args...)
+	case "player":
+		player(args...)
 	case "rest":
 		rest(args...)
 	case "board":
@@ -3,10 +3,20 @@ package main
 import (
 	"fmt"
 	"log"
+
+	"github.com/ymattw/googs"
 )
 
 func overview() {
 	client := loadClient()
+	announcements, err := client.Announcements()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, a := range announcements {
+		fmt.Printf("[%s] %s %s\n", a.Type, a.Title, a.Body)
+	}
+
 	v, err := client.Overview()
 	if err != nil {
 		log.Fatal(err)
@@ -29,4 +39,16 @@ func overview() {
 			len(g.Moves),
 			whoseTurn)
 	}
+
+	history, err := client.MyGameHistory(1, 10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\nRecent finished games (total %d games)\n", history.Total)
+	for _, g := range history.Results {
+		if g.Phase != googs.FinishedPhase {
+			continue
+		}
+		fmt.Printf("  %d %-10q %s vs %s\n", g.ID, g.Name, g.Black, g.White)
+	}
 }
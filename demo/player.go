@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/ymattw/googs"
+)
+
+func player(args ...string) {
+	if len(args) != 1 {
+		log.Fatal("Syntax: player <playerID|username>")
+	}
+
+	client := loadClient()
+	var p *googs.User
+	var err error
+	if id, perr := strconv.ParseInt(args[0], 10, 64); perr == nil {
+		p, err = client.PlayerByID(id)
+	} else {
+		p, err = client.PlayerByUsername(args[0])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s\n", formatObject(p))
+}
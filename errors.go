@@ -0,0 +1,108 @@
+package googs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrAuthRequired is returned by methods which need a logged in Client when
+// called on an anonymous one.
+var ErrAuthRequired = errors.New("authentication required")
+
+// ErrNotCancellable is returned by GameCancel when the game is known to be past
+// the point it can be cancelled, where cancelling would be a resignation.
+var ErrNotCancellable = errors.New("game can no longer be cancelled")
+
+// ErrNotConnected is returned when sending a websocket event before the Client
+// connects or after Disconnect().
+var ErrNotConnected = errors.New("websocket not connected")
+
+// errDisconnected is returned when redialing after Disconnect() was called.
+var errDisconnected = errors.New("client disconnected")
+
+// APIError is returned by REST requests when OGS responds with a non-2xx
+// status, use errors.As to inspect it.
+type APIError struct {
+	StatusCode int
+	Status     string // E.g. "404 Not Found"
+	URL        string
+	Body       []byte // Raw response body
+	Detail     string // Error message from OGS if any
+}
+
+func newAPIError(statusCode int, url string, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		URL:        url,
+		Body:       body,
+	}
+
+	// OGS usually responds {"detail": "..."}, sometimes {"error": "..."}
+	var msg struct {
+		Detail string
+		Error  string
+	}
+	if json.Unmarshal(body, &msg) == nil {
+		e.Detail = cond(msg.Detail != "", msg.Detail, msg.Error)
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s -> %s", e.URL, e.Status)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// ValidationError is returned when an argument is rejected before sending any
+// request.
+type ValidationError struct {
+	Field   string
+	Value   string
+	Allowed []string // Known values if applicable
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Allowed) > 0 {
+		return fmt.Sprintf("invalid %s %q, want one of %q", e.Field, e.Value, e.Allowed)
+	}
+	return fmt.Sprintf("invalid %s %q", e.Field, e.Value)
+}
+
+// IsUnauthorized returns whether err is an *APIError with 401 status, i.e. the
+// credentials are invalid or expired.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsRateLimited returns whether err is an *APIError with 429 status.
+func IsRateLimited(err error) bool {
+	return statusCode(err) == http.StatusTooManyRequests
+}
+
+// IsConflict returns whether err is an *APIError with 409 status, e.g. when
+// blocking a player already blocked.
+func IsConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict
+}
+
+// isAuthError returns whether err is caused by invalid or expired
+// credentials.
+func isAuthError(err error) bool {
+	code := statusCode(err)
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// statusCode returns the HTTP status code carried by err, or 0.
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -1,6 +1,6 @@
 module github.com/ymattw/googs
 
-go 1.18
+go 1.21
 
 require github.com/graarh/golang-socketio v0.0.0-20170510162725-2c44953b9b5f
 
@@ -0,0 +1,55 @@
+package googs
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+)
+
+// sensitiveKeys are payload and query keys whose values are never logged.
+var sensitiveKeys = map[string]bool{
+	"access_token":      true,
+	"refresh_token":     true,
+	"client_secret":     true,
+	"password":          true,
+	"jwt":               true,
+	"user_jwt":          true,
+	"chat_auth":         true,
+	"notification_auth": true,
+	"token":             true, // Revoked by Logout()
+}
+
+// log logs at the level, nothing is logged without a logger.
+func (c *Client) log(level slog.Level, msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Log(context.Background(), level, msg, args...)
+	}
+}
+
+func (c *Client) debug(msg string, args ...any) {
+	c.log(slog.LevelDebug, msg, args...)
+}
+
+func (c *Client) warn(msg string, args ...any) {
+	c.log(slog.LevelWarn, msg, args...)
+}
+
+// redacted returns a copy of an event payload or request parameters with
+// sensitive values masked, other types are returned as is.
+func redacted(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for key, value := range v {
+			res[key] = cond[any](sensitiveKeys[key], "REDACTED", value)
+		}
+		return res
+	case url.Values:
+		res := make(url.Values, len(v))
+		for key, values := range v {
+			res[key] = cond(sensitiveKeys[key], []string{"REDACTED"}, values)
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,55 @@
+package googs
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestClient_Logging(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"access_token": "secret-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"user_jwt": "secret-jwt"})
+	})
+	var logs bytes.Buffer
+	sock := &fakeSocket{}
+	c := newTestClient(t, mux)
+	c.logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c.dial = func() (socket, error) { return sock, nil }
+
+	data := url.Values{"grant_type": {"password"}, "username": {"alice"}, "password": {"secret-password"}}
+	if err := c.authenticate(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+	c.OnMove(123, func(*GameMove) {})
+	c.emit(context.Background(), "game/move", map[string]any{"game_id": 123, "move": "aa"})
+	c.emit(context.Background(), "notification/connect", map[string]any{"player_id": 1, "notification_auth": "secret-auth"})
+	sock.receive("game/123/move", `{"game_id": 123}`)
+	sock.receive("game/123/move", `"bad"`)
+
+	got := logs.String()
+	for _, want := range []string{
+		"uri=/oauth2/token/", "status=200", "latency=",
+		"state=Connected", "event=authenticate", "event=game/move", `msg=Received event=game/123/move`,
+		`level=WARN msg="Failed to decode event" event=game/123/move`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("logs want %q, got\n%s", want, got)
+		}
+	}
+	for _, secret := range []string{"secret-password", "secret-token", "secret-jwt", "secret-auth"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("logs want %q redacted, got\n%s", secret, got)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,7 @@ type User struct {
 	Country      string
 	Professional bool
 	About        string
+	RealName     string `json:"real_name"`
 	Ranking      float32
 	Ratings      OGSRating
 	IsBot        bool   `json:"is_bot"`
@@ -35,6 +37,14 @@ type User struct {
 	UIClass      string `json:"ui_class"`
 }
 
+// UpdateProfileParams are profile fields to change via UpdateProfile, nil
+// fields are left unchanged.
+type UpdateProfileParams struct {
+	Country  *string `json:"country,omitempty"` // Two letter code, e.g. "us"
+	About    *string `json:"about,omitempty"`
+	RealName *string `json:"real_name,omitempty"`
+}
+
 // Glicko2 contains Glicko2 ratings of a user.
 type Glicko2 struct {
 	Deviation   float32
@@ -72,8 +82,18 @@ type Timestamp struct {
 }
 
 // UnmarshalJSON is a customized JSON decoder for properly handling timestamps
-// represented in both seconds or milliseconds.
+// represented in both seconds or milliseconds, or as RFC 3339 strings used by
+// some REST APIs. null is left as zero time.
 func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if s, err := strconv.Unquote(string(b)); err == nil {
+		if t.Time, err = time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("Timestamp.UnmarshalJSON: expected an RFC 3339 time, but got %q: %w", s, err)
+		}
+		return nil
+	}
 	ts, err := strconv.ParseInt(string(b), 10, 64)
 	if err != nil {
 		return fmt.Errorf("Timestamp.UnmarshalJSON: expected a numeric Unix timestamp, but got %q: %w", string(b), err)
@@ -86,6 +106,28 @@ func (t *Timestamp) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Bool01 is a customized bool for fields the server sends either as a number
+// 0/1 or as a boolean.
+type Bool01 bool
+
+// UnmarshalJSON is a customized JSON decoder treating any non-zero number as
+// true, null is left as false.
+func (b *Bool01) UnmarshalJSON(data []byte) error {
+	switch s := string(data); s {
+	case "null":
+		return nil
+	case "true", "false":
+		*b = s == "true"
+		return nil
+	}
+	n, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("Bool01.UnmarshalJSON: expected a number or boolean, but got %q: %w", string(data), err)
+	}
+	*b = n != 0
+	return nil
+}
+
 type GamePhase string
 
 const (
@@ -94,16 +136,34 @@ const (
 	FinishedPhase     GamePhase = "finished"
 )
 
+// Valid returns whether the phase is one of the known GamePhase constants,
+// values from the server are not validated on unmarshal.
+func (p GamePhase) Valid() bool {
+	switch p {
+	case PlayPhase, StoneRemovalPhase, FinishedPhase:
+		return true
+	}
+	return false
+}
+
+// IsTerminal returns whether the game is over.
+func (p GamePhase) IsTerminal() bool {
+	return p == FinishedPhase
+}
+
 type Game struct {
+	baseURL string // Set by Client, ogsBaseURL when empty
+
 	AgaHandicapScoring            bool  `json:"aga_handicap_scoring"`
 	AllowSelfCapture              bool  `json:"allow_self_capture"`
 	AllowSuperko                  bool  `json:"allow_superko"`
 	AutomaticStoneRemoval         bool  `json:"automatic_stone_removal"`
 	BlackPlayerID                 int64 `json:"black_player_id"`
 	Clock                         Clock
-	GameID                        int64  `json:"game_id"`
-	GameName                      string `json:"game_name"`
-	GroupIDs                      []any  `json:"group_ids"` // Can be []int or []string, depending on content
+	GameID                        int64    `json:"game_id"`
+	GameName                      string   `json:"game_name"`
+	GroupIDs                      []int64  `json:"group_ids"`
+	GroupNames                    []string `json:"-"` // Non-numeric group IDs, OGS sends strings sometimes
 	Handicap                      int
 	HandicapRankDifference        float32 `json:"handicap_rank_difference"`
 	Height                        int
@@ -120,7 +180,8 @@ type Game struct {
 	Ranked                        bool
 	Removed                       string
 	Rengo                         bool
-	Rules                         string
+	Teams                         RengoTeams `json:"rengo_teams"` // Only for Rengo games
+	Rules                         RuleSet
 	Score                         Score       // Only available when Phase is "finished"
 	ScoreHandicap                 bool        `json:"score_handicap"`
 	ScorePasses                   bool        `json:"score_passes"`
@@ -173,8 +234,41 @@ func (g *Game) String() string {
 		whoseTurn)
 }
 
+// UnmarshalJSON is a customized JSON decoder for properly handling group IDs
+// sent as numbers or strings, non-numeric ones are kept in GroupNames.
+func (g *Game) UnmarshalJSON(data []byte) error {
+	type game Game // Avoid recursion
+	var res struct {
+		game
+		GroupIDs []any `json:"group_ids"` // Shadows game.GroupIDs
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*g = Game(res.game)
+	for _, id := range res.GroupIDs {
+		switch id := id.(type) {
+		case float64:
+			g.GroupIDs = append(g.GroupIDs, int64(id))
+		case string:
+			if n, err := strconv.ParseInt(id, 10, 64); err == nil {
+				g.GroupIDs = append(g.GroupIDs, n)
+			} else {
+				g.GroupNames = append(g.GroupNames, id)
+			}
+		}
+	}
+	return nil
+}
+
+// RuleSet returns the rules of the game.
+func (g *Game) RuleSet() RuleSet {
+	return g.Rules
+}
+
+// URL returns link to the game on the server it was fetched from.
 func (g *Game) URL() string {
-	return fmt.Sprintf("%s/game/%d", ogsBaseURL, g.GameID)
+	return fmt.Sprintf("%s/game/%d", cond(g.baseURL != "", g.baseURL, ogsBaseURL), g.GameID)
 }
 
 func (g *Game) BoardSize() int {
@@ -189,8 +283,42 @@ func (g *Game) IsMyTurn(myUserID int64) bool {
 	return g.Clock.CurrentPlayerID == myUserID
 }
 
+// Opponent returns the opponent, or the member of the opposing team currently
+// in Players in a Rengo game.
 func (g *Game) Opponent(myUserID int64) Player {
-	return cond(g.Players.Black.ID == myUserID, g.Players.White, g.Players.Black)
+	return cond(g.PlayerColor(myUserID) == PlayerBlack, g.Players.White, g.Players.Black)
+}
+
+// RengoTeams returns members of both teams in playing order, a single player
+// each for a non Rengo game.
+func (g *Game) RengoTeams() (black, white []Player) {
+	if !g.Rengo {
+		return []Player{g.Players.Black}, []Player{g.Players.White}
+	}
+	return g.Teams.Black, g.Teams.White
+}
+
+// PlayerColor returns the color the user plays, in a Rengo game the color of
+// the user's team. PlayerUnknown is returned for a non player.
+func (g *Game) PlayerColor(userID int64) PlayerColor {
+	black, white := g.RengoTeams()
+	isMember := func(p Player) bool { return p.ID == userID }
+	switch {
+	case userID == 0:
+		return PlayerUnknown
+	case slices.ContainsFunc(black, isMember):
+		return PlayerBlack
+	case slices.ContainsFunc(white, isMember):
+		return PlayerWhite
+	}
+	return PlayerUnknown
+}
+
+// ComputeClock is like Clock.ComputeClock but also works for Rengo games, in
+// which the team clock runs for whichever member is to move.
+func (g *Game) ComputeClock(player PlayerColor) *ComputedClock {
+	isTurn := g.PlayerColor(g.Clock.CurrentPlayerID) == player
+	return g.Clock.computeClock(&g.TimeControl, player, time.Now(), isTurn)
 }
 
 func (g *Game) PlayerByID(userID int64) Player {
@@ -205,6 +333,38 @@ func (g *Game) WhitePlayer() Player {
 	return g.Players.White
 }
 
+// UpdateRemovedStones applies a removal change during stone removal phase,
+// which invalidates acceptances of both players.
+func (g *Game) UpdateRemovedStones(r *RemovedStones) {
+	g.Removed = r.AllRemoved
+	g.Players.Black.AcceptedStones = nil
+	g.Players.White.AcceptedStones = nil
+}
+
+// UpdateRemovedStonesAccepted records the acceptance of a player, and the
+// result when both players accepted.
+func (g *Game) UpdateRemovedStonesAccepted(r *RemovedStonesAccepted) {
+	stones := r.Stones
+	switch r.PlayerID {
+	case g.Players.Black.ID:
+		g.Players.Black.AcceptedStones = &stones
+	case g.Players.White.ID:
+		g.Players.White.AcceptedStones = &stones
+	}
+	if r.Phase == FinishedPhase {
+		g.Phase = r.Phase
+		g.Score = r.Score
+		g.Outcome = r.Outcome
+		g.WinnerID = r.WinnerID
+	}
+}
+
+// RemovalAccepted returns whether both players accepted the same removal.
+func (g *Game) RemovalAccepted() bool {
+	b, w := g.Players.Black.AcceptedStones, g.Players.White.AcceptedStones
+	return b != nil && w != nil && *b == *w
+}
+
 func (g *Game) BlackPlayerTitle() string {
 	return "(B) " + g.Players.Black.String()
 }
@@ -253,6 +413,9 @@ func (g *Game) WhoseTurn(state *GameState) PlayerColor {
 	if state == nil {
 		return PlayerUnknown
 	}
+	if color := g.PlayerColor(state.PlayerToMove); color != PlayerUnknown {
+		return color
+	}
 	return cond(state.PlayerToMove == g.BlackPlayer().ID, PlayerBlack, PlayerWhite)
 }
 
@@ -293,14 +456,67 @@ type Clock struct {
 	BlackTime       PlayerTime `json:"black_time"`
 	CurrentPlayerID int64      `json:"current_player"`
 	Expiration      Timestamp
-	GameID          int64     `json:"game_id"`
-	LastMove        Timestamp `json:"last_move"`
-	PausedSince     Timestamp `json:"paused_since"`
+	GameID          int64           `json:"game_id"`
+	LastMove        Timestamp       `json:"last_move"`
+	PausedSince     Timestamp       `json:"paused_since"`
+	Pause           *GamePauseState // Nil if never paused
 	Title           string
 	WhitePlayerID   int64      `json:"white_player_id"`
 	WhiteTime       PlayerTime `json:"white_time"`
 	StartMode       bool
 	Now             Timestamp // Only for OnClock
+
+	// Drift is the local clock minus the OGS clock, set from the latest
+	// Client.Ping() or Now of clock events for clocks received via OnClock
+	// and OnGameData.
+	// ComputeClock counts elapsed time in OGS clock, i.e. now minus Drift,
+	// so computed times match the OGS web UI despite a skewed local clock.
+	Drift time.Duration `json:"-"`
+}
+
+// IsPaused returns whether the clock is stopped for both players.
+func (c *Clock) IsPaused() bool {
+	return !c.pausedSince().IsZero() || (c.Pause != nil && c.Pause.Paused)
+}
+
+func (c *Clock) pausedSince() Timestamp {
+	if c.PausedSince.IsZero() && c.Pause != nil {
+		return c.Pause.PausedSince
+	}
+	return c.PausedSince
+}
+
+// GamePauseState describes why a game is paused, e.g. manually by a player,
+// on vacation, or on weekends for a correspondence game.
+type GamePauseState struct {
+	Paused      bool
+	PausedSince Timestamp `json:"paused_since"`
+
+	// Active pauses keyed by reason, e.g. "paused" (manually, with the
+	// pausing player), "weekend", "system" or "vacation-<player ID>".
+	PauseControl map[string]json.RawMessage `json:"pause_control"`
+}
+
+// PausingPlayerID returns the user ID of the player paused the game
+// manually, or zero. An error is returned when the pause is malformed.
+func (p *GamePauseState) PausingPlayerID() (int64, error) {
+	data, ok := p.PauseControl["paused"]
+	if !ok {
+		return 0, nil
+	}
+	var paused struct {
+		PausingPlayerID int64 `json:"pausing_player_id"`
+	}
+	if err := json.Unmarshal(data, &paused); err != nil {
+		return 0, fmt.Errorf("invalid pause_control.paused: %w", err)
+	}
+	return paused.PausingPlayerID, nil
+}
+
+// OnVacation returns whether the game is paused by vacation of the player.
+func (p *GamePauseState) OnVacation(playerID int64) bool {
+	_, ok := p.PauseControl[fmt.Sprintf("vacation-%d", playerID)]
+	return ok
 }
 
 type ComputedClock struct {
@@ -310,37 +526,60 @@ type ComputedClock struct {
 	PeriodTimeLeft float64 // Byoyomi only
 	MovesLeft      int     // Canadian only
 	BlockTimeLeft  float64 // Canadian only
+	Increment      float64 // Fischer only, added after every move
 	SuddenDeath    bool
 	TimedOut       bool
 }
 
 // ComputeClock returns a computed clock struct of the given players.
+//
+// NOTE: Weekends are not accounted for, while OGS doesn't decrement the clock
+// of correspondence games during weekends when TimeControl.PauseOnWeekends is
+// set, so the time left may be underestimated then.
 func (c *Clock) ComputeClock(tc *TimeControl, player PlayerColor) *ComputedClock {
+	return c.ComputeClockAt(tc, player, time.Now())
+}
+
+// ComputeClockAt is like ComputeClock but computes the clock at the given
+// local time, Drift is applied to it the same way.
+func (c *Clock) ComputeClockAt(tc *TimeControl, player PlayerColor, now time.Time) *ComputedClock {
+	if c == nil {
+		return &ComputedClock{System: ClockUnknown}
+	}
+	isTurn := c.CurrentPlayerID == cond(player == PlayerBlack, c.BlackPlayerID, c.WhitePlayerID)
+	return c.computeClock(tc, player, now, isTurn)
+}
+
+func (c *Clock) computeClock(tc *TimeControl, player PlayerColor, now time.Time, isTurn bool) *ComputedClock {
 	var t PlayerTime
-	var isTurn bool
 
+	// An unknown system, e.g. added to OGS later, is decoded as is
 	unknownClock := ComputedClock{System: ClockUnknown}
-	if c == nil {
+	if c == nil || !tc.System.Valid() {
 		return &unknownClock
 	}
 
 	switch player {
 	case PlayerBlack:
 		t = c.BlackTime
-		isTurn = c.CurrentPlayerID == c.BlackPlayerID
 	case PlayerWhite:
 		t = c.WhiteTime
-		isTurn = c.CurrentPlayerID == c.WhitePlayerID
 	default:
 		return &unknownClock
 	}
 
-	// Pause clock if not turn or game has not started yet
-	elapsed := cond(isTurn && !c.StartMode, time.Since(c.LastMove.Time).Seconds(), 0)
+	// Pause clock if not turn or game has not started yet, a paused game
+	// only counts time elapsed before the pause.
+	now = now.Add(-c.Drift)
+	elapsed := cond(isTurn && !c.StartMode, now.Sub(c.LastMove.Time).Seconds(), 0)
+	if c.IsPaused() {
+		since := c.pausedSince()
+		elapsed = cond(isTurn && !c.StartMode && !since.IsZero(), math.Max(0, since.Sub(c.LastMove.Time).Seconds()), 0)
+	}
 
 	switch tc.System {
 
-	case ClockAbsolute, ClockFischer:
+	case ClockAbsolute:
 		mainTime := cond(isTurn, math.Max(0, t.ThinkingTime-elapsed), t.ThinkingTime)
 		return &ComputedClock{
 			System:      tc.System,
@@ -349,6 +588,22 @@ func (c *Clock) ComputeClock(tc *TimeControl, player PlayerColor) *ComputedClock
 			TimedOut:    mainTime < 1e-7,
 		}
 
+	case ClockFischer:
+		// OGS adds the increment to ThinkingTime once a move is made,
+		// capped at MaxTime.
+		thinkingTime := t.ThinkingTime
+		if tc.MaxTime > 0 {
+			thinkingTime = math.Min(thinkingTime, tc.MaxTime)
+		}
+		mainTime := cond(isTurn, math.Max(0, thinkingTime-elapsed), thinkingTime)
+		return &ComputedClock{
+			System:      tc.System,
+			MainTime:    mainTime,
+			Increment:   tc.TimeIncrement,
+			SuddenDeath: mainTime < 10,
+			TimedOut:    mainTime < 1e-7,
+		}
+
 	case ClockByoyomi:
 		var periodsLeft int
 		var mainTime, periodTimeLeft, overTime float64
@@ -370,7 +625,7 @@ func (c *Clock) ComputeClock(tc *TimeControl, player PlayerColor) *ComputedClock
 				periodsLeft -= int(periodsUsed)
 				periodsLeft = cond(periodsLeft > 0, periodsLeft, 0)
 				periodTimeLeft = tc.PeriodTime - (overTime - periodsUsed*tc.PeriodTime)
-				periodTimeLeft = cond(periodTimeLeft > 1e-7, periodTimeLeft, 0)
+				periodTimeLeft = cond(periodTimeLeft > 1e-7 && periodsLeft > 0, periodTimeLeft, 0)
 			}
 		} else {
 			periodsLeft = t.Periods
@@ -383,7 +638,7 @@ func (c *Clock) ComputeClock(tc *TimeControl, player PlayerColor) *ComputedClock
 			PeriodsLeft:    periodsLeft,
 			PeriodTimeLeft: periodTimeLeft,
 			SuddenDeath:    periodsLeft <= 1,
-			TimedOut:       mainTime < 1e-7 && periodsLeft < 0,
+			TimedOut:       mainTime < 1e-7 && periodsLeft <= 0,
 		}
 
 	case ClockCanadian:
@@ -443,8 +698,11 @@ func (c ComputedClock) String() string {
 	}
 
 	switch c.System {
-	case ClockAbsolute, ClockFischer, ClockSimple:
+	case ClockAbsolute, ClockSimple:
 		return fmt.Sprintf("%s%s", prettyTime(c.MainTime), cond(c.SuddenDeath, " (SD)", ""))
+	case ClockFischer:
+		increment := cond(c.Increment > 0, " +"+prettyTime(c.Increment), "")
+		return fmt.Sprintf("%s%s%s", prettyTime(c.MainTime), increment, cond(c.SuddenDeath, " (SD)", ""))
 	case ClockByoyomi:
 		if c.SuddenDeath {
 			return fmt.Sprintf("%s (SD)", prettyTime(c.PeriodTimeLeft))
@@ -525,6 +783,59 @@ type Players struct {
 	White Player
 }
 
+// GamePlayerUpdate is the response of Realtime API "game/:id/player_update",
+// with player IDs only.
+type GamePlayerUpdate struct {
+	Players struct {
+		Black int64
+		White int64
+	}
+	RengoTeams struct {
+		Black []int64
+		White []int64
+	} `json:"rengo_teams"` // Only for Rengo games
+}
+
+// RengoTeams is the members of both teams of a Rengo game.
+type RengoTeams struct {
+	Black []Player
+	White []Player
+}
+
+// RuleSet is the rules of a game, which determine how it is scored.
+type RuleSet string
+
+const (
+	RulesJapanese   RuleSet = "japanese"
+	RulesChinese    RuleSet = "chinese"
+	RulesAGA        RuleSet = "aga"
+	RulesNewZealand RuleSet = "new_zealand"
+	RulesIng        RuleSet = "ing"
+	RulesKorean     RuleSet = "korean"
+)
+
+// orDefault returns the rules, or RulesJapanese which OGS defaults to when
+// empty.
+func (r RuleSet) orDefault() RuleSet {
+	return cond(r == "", RulesJapanese, r)
+}
+
+// ScoresPrisoners returns whether captured stones count in the score, i.e.
+// territory scoring.
+func (r RuleSet) ScoresPrisoners() bool {
+	return r == RulesJapanese || r == RulesKorean
+}
+
+// UsesAreaScoring returns whether stones on the board count in the score
+// along with territory. Neither is true for unknown rules.
+func (r RuleSet) UsesAreaScoring() bool {
+	switch r {
+	case RulesChinese, RulesAGA, RulesNewZealand, RulesIng:
+		return true
+	}
+	return false
+}
+
 type ClockSystem string
 
 const (
@@ -537,10 +848,19 @@ const (
 	ClockNone     ClockSystem = "none"
 )
 
+// GameSpeed is the speed category of a game, derived from its time control.
+type GameSpeed string
+
+const (
+	SpeedBlitz          GameSpeed = "blitz"
+	SpeedLive           GameSpeed = "live"
+	SpeedCorrespondence GameSpeed = "correspondence"
+)
+
 type TimeControl struct {
-	System          ClockSystem
-	Speed           string
-	PauseOnWeekends bool `json:"pause_on_weekends"`
+	System          ClockSystem `json:"system"`
+	Speed           GameSpeed   `json:"speed"`
+	PauseOnWeekends bool        `json:"pause_on_weekends"`
 
 	// Absolute
 	TotalTime float64 `json:"total_time"`
@@ -548,9 +868,9 @@ type TimeControl struct {
 	// Byoyomi
 	MainTime   float64 `json:"main_time"`   // Also for Canadian
 	PeriodTime float64 `json:"period_time"` // Also for Canadian
-	Periods    int
-	PeriodsMax int `json:"periods_max"`
-	PeriodsMin int `json:"periods_min"`
+	Periods    int     `json:"periods"`
+	PeriodsMax int     `json:"periods_max"`
+	PeriodsMin int     `json:"periods_min"`
 
 	// Canadian
 	StonesPerPeriod int `json:"stones_per_period"`
@@ -564,6 +884,33 @@ type TimeControl struct {
 	PerMove float64 `json:"per_move"`
 }
 
+// Valid returns whether the system is a clock system known by the package,
+// ClockUnknown is not. TimeControl keeps an unknown system as decoded, clocks
+// of it are computed as ClockUnknown.
+func (s ClockSystem) Valid() bool {
+	switch s {
+	case ClockAbsolute, ClockByoyomi, ClockCanadian, ClockFischer, ClockSimple, ClockNone:
+		return true
+	}
+	return false
+}
+
+// IsBlitz returns whether the time control is for a blitz game.
+func (t *TimeControl) IsBlitz() bool {
+	return t.Speed == SpeedBlitz
+}
+
+// IsLive returns whether the time control is for a live game.
+func (t *TimeControl) IsLive() bool {
+	return t.Speed == SpeedLive
+}
+
+// IsCorrespondence returns whether the time control is for a correspondence
+// game.
+func (t *TimeControl) IsCorrespondence() bool {
+	return t.Speed == SpeedCorrespondence
+}
+
 func (t TimeControl) String() string {
 	switch t.System {
 	case ClockAbsolute:
@@ -622,12 +969,49 @@ func (m *Move) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// A1 returns the move in format "A1", or "pass" for a pass. A move out of the
+// board is formatted as in OriginCoordinate.
+func (m Move) A1(boardSize int) string {
+	if m.IsPass() {
+		return "pass"
+	}
+	a1, err := m.ToA1Coordinate(boardSize)
+	if err != nil {
+		return m.OriginCoordinate.String()
+	}
+	return a1.String()
+}
+
+// FormatMoves returns moves in format "A1", see Move.A1.
+func FormatMoves(moves []Move, boardSize int) []string {
+	res := make([]string, len(moves))
+	for i, m := range moves {
+		res[i] = m.A1(boardSize)
+	}
+	return res
+}
+
 // GameOverview is almost identical to Game but decoded using a different json
 // tag.
 type GameOverview struct {
 	Game `json:"json"` // Embedded
 }
 
+// UnmarshalJSON decodes the game from the "json" field, the promoted
+// Game.UnmarshalJSON would otherwise decode the overview as a flat game.
+func (g *GameOverview) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Game json.RawMessage `json:"json"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if res.Game == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Game, &g.Game)
+}
+
 type GameMove struct {
 	GameID     int64 `json:"game_id"`
 	Move       Move
@@ -663,23 +1047,67 @@ func (g *GameState) IsMyTurn(myUserID int64) bool {
 	return g.PlayerToMove == myUserID
 }
 
+// Cancellable returns whether the game can still be cancelled without rating
+// impact, i.e. it's in play phase and not both players have made a move.
+func (g *GameState) Cancellable() bool {
+	return g.Phase == PlayPhase && g.MoveNumber < 2
+}
+
 func (g *GameState) RemovalString() string {
 	var pairs []string
 	for y, row := range g.Removal {
 		for x, val := range row {
 			if val == 1 {
-				move := fmt.Sprintf("%c%c", rune('a'+x), rune('a'+y)) // SGF
-				pairs = append(pairs, move)
+				pairs = append(pairs, OriginCoordinate{X: x, Y: y}.ToSGF())
 			}
 		}
 	}
 	return strings.Join(pairs, "")
 }
 
+// ConditionalMoveTree is a plan of moves in SGF coordinates, played
+// automatically in correspondence games as the opponent moves.
+type ConditionalMoveTree struct {
+	// My move to play, empty at the root when waiting for the opponent.
+	Move string
+
+	// Plans keyed by opponent moves responding to Move.
+	Responses map[string]*ConditionalMoveTree
+}
+
+// MarshalJSON encodes the tree the same way as OGS, e.g. ["dd", {"pp":
+// ["dp", {}]}] plays "dd" and then "dp" if the opponent plays "pp".
+func (t *ConditionalMoveTree) MarshalJSON() ([]byte, error) {
+	responses := t.Responses
+	if responses == nil {
+		responses = map[string]*ConditionalMoveTree{}
+	}
+	return json.Marshal([]any{cond[any](t.Move == "", nil, t.Move), responses})
+}
+
+// UnmarshalJSON decodes the tree encoded by MarshalJSON.
+func (t *ConditionalMoveTree) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 2 {
+		return fmt.Errorf("ConditionalMoveTree.UnmarshalJSON: expected 2 elements, got %d", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &t.Move); err != nil { // No-op for null
+		return err
+	}
+	return json.Unmarshal(raw[1], &t.Responses)
+}
+
 // RemovedStones is the response of Realtime API "game/:id/removed_stones".
 type RemovedStones struct {
 	// Result removal string is a sequence of SGF coordinates, e.g.
-	// "edhdid" is equivalent to origin coordinates (3,4) (3,7) (3,8).
+	// "edhdid" is equivalent to origin coordinates [4,3] [7,3] [8,3], see
+	// ParseSGFVertices.
 	AllRemoved string `json:"all_removed"`
 
 	// Removal changes
@@ -687,12 +1115,18 @@ type RemovedStones struct {
 	Stones  string
 }
 
+// RemovedCoordinates decodes AllRemoved into coordinates, e.g. to render dead
+// stones during stone removal phase.
+func (r *RemovedStones) RemovedCoordinates(boardSize int) ([]OriginCoordinate, error) {
+	return ParseSGFVertices(r.AllRemoved, boardSize)
+}
+
 // RemovedStonesAccepted is the response of Realtime API "game/:id/removed_stones_accepted".
 type RemovedStonesAccepted struct {
 	PlayerID int64 `json:"player_id"`
 
 	// Result removal string is a sequence of SGF coordinates, e.g.
-	// "edhdid" is equivalent to origin coordinates (3,4) (3,7) (3,8).
+	// "edhdid" is equivalent to origin coordinates [4,3] [7,3] [8,3].
 	Stones  string
 	Players Players
 
@@ -706,6 +1140,11 @@ type RemovedStonesAccepted struct {
 	WinnerID int64 `json:"winner"`
 }
 
+// RemovedCoordinates decodes the accepted Stones into coordinates.
+func (r *RemovedStonesAccepted) RemovedCoordinates(boardSize int) ([]OriginCoordinate, error) {
+	return ParseSGFVertices(r.Stones, boardSize)
+}
+
 func (r *RemovedStonesAccepted) Result() string {
 	if r.Phase != FinishedPhase {
 		return ""
@@ -793,6 +1232,85 @@ func (c A1Coordinate) ToOriginCoordinate(boardSize int) (*OriginCoordinate, erro
 	return &OriginCoordinate{X: x, Y: y}, nil
 }
 
+// ParseGTPVertex parses a vertex in GTP (Go Text Protocol), e.g. "Q16" or
+// "pass" which is returned as OriginCoordinate{-1, -1}. GTP uses the same
+// letters as A1Coordinate, case insensitive.
+func ParseGTPVertex(s string, boardSize int) (*OriginCoordinate, error) {
+	if strings.EqualFold(s, "pass") {
+		return &OriginCoordinate{X: -1, Y: -1}, nil
+	}
+	a1, err := NewA1Coordinate(s)
+	if err != nil {
+		return nil, err
+	}
+	return a1.ToOriginCoordinate(boardSize)
+}
+
+// ToGTP returns the vertex in GTP, e.g. "Q16" or "pass", see ParseGTPVertex.
+// Empty string is returned for a coordinate out of the board.
+func (c OriginCoordinate) ToGTP(boardSize int) string {
+	if c.IsPass() {
+		return "pass"
+	}
+	a1, err := c.ToA1Coordinate(boardSize)
+	if err != nil {
+		return ""
+	}
+	return a1.String()
+}
+
+// ToSGF returns the point in SGF coordinates, e.g. "dp" for [3,15], a pass is
+// ".." as OGS sends.
+func (c OriginCoordinate) ToSGF() string {
+	if c.IsPass() {
+		return ".."
+	}
+	return fmt.Sprintf("%c%c", rune('a'+c.X), rune('a'+c.Y))
+}
+
+// ParseSGFVertex parses a point in SGF coordinates, e.g. "dp". A pass, either
+// "..", "" or "tt" on boards up to 19x19, is returned as OriginCoordinate{-1, -1}.
+func ParseSGFVertex(s string, boardSize int) (*OriginCoordinate, error) {
+	if s == "" || s == ".." || (s == "tt" && boardSize <= 19) {
+		return &OriginCoordinate{X: -1, Y: -1}, nil
+	}
+	if len(s) != 2 {
+		return nil, fmt.Errorf("invalid SGF coordinate %q", s)
+	}
+	c := OriginCoordinate{X: int(s[0]) - 'a', Y: int(s[1]) - 'a'}
+	if c.X < 0 || c.X >= boardSize || c.Y < 0 || c.Y >= boardSize {
+		return nil, fmt.Errorf("SGF coordinate %q is out of board bounds [0-%d]", s, boardSize-1)
+	}
+	return &c, nil
+}
+
+// ParseSGFVertices parses a sequence of SGF coordinates as used by
+// RemovedStones, e.g. "edhdid".
+func ParseSGFVertices(s string, boardSize int) ([]OriginCoordinate, error) {
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("invalid SGF coordinates %q: odd length", s)
+	}
+	var coords []OriginCoordinate
+	for i := 0; i < len(s); i += 2 {
+		c, err := ParseSGFVertex(s[i:i+2], boardSize)
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, *c)
+	}
+	return coords, nil
+}
+
+// SGFVertices packs coordinates into a sequence of SGF coordinates as used by
+// RemovedStones, the reverse of ParseSGFVertices.
+func SGFVertices(coords []OriginCoordinate) string {
+	var b strings.Builder
+	for _, c := range coords {
+		b.WriteString(c.ToSGF())
+	}
+	return b.String()
+}
+
 type GameListWhere struct {
 	HideRanked     bool    `json:"hide_ranked"`
 	HideUnranked   bool    `json:"hide_unranked"`
@@ -827,7 +1345,7 @@ type GameListEntry struct {
 	Width            int
 	Height           int
 	MoveNumber       int `json:"move_number"`
-	Paused           int // XXX: server response is a number 0/1
+	Paused           Bool01
 	Private          bool
 	Black            Player
 	White            Player
@@ -848,6 +1366,209 @@ type GameListEntry struct {
 	MalkovichPresent bool `json:"malkovich_present"`
 }
 
+// Tournament contains general information of a tournament.
+type Tournament struct {
+	ID           int64
+	Name         string
+	Description  string
+	GameRules    RuleSet     `json:"rules"`
+	TimeControl  TimeControl `json:"time_control_parameters"`
+	StartTime    Timestamp   `json:"time_start"`
+	EndTime      Timestamp   `json:"ended"` // Zero if not ended yet
+	Participants int         `json:"player_count"`
+}
+
+// Ladder contains general information of a ladder.
+type Ladder struct {
+	ID      int64
+	Name    string
+	GroupID int64 `json:"group"`        // Zero for site wide ladders
+	Size    int   `json:"board_size"`   // Board size
+	Players int   `json:"player_count"` // Number of players
+}
+
+// ChallengeRequest describes an open challenge to create.
+type ChallengeRequest struct {
+	PlayerID    int64 // Player to challenge, zero for an open challenge
+	Name        string
+	Size        int     // Board size, e.g. 19
+	Rules       RuleSet // RulesJapanese when empty
+	Ranked      bool
+	Handicap    int
+	Komi        *float32 // Automatic by rules when nil
+	Color       string   // "automatic" (default), "random", "black" or "white"
+	TimeControl TimeControl
+}
+
+// ChallengeResponse is the result of CreateChallenge.
+type ChallengeResponse struct {
+	ChallengeID int64 `json:"challenge"`
+	GameID      int64 `json:"game"` // Zero if not matched yet
+}
+
+// Challenge is a game offered by the challenger.
+type Challenge struct {
+	ID          int64
+	Challenger  Player
+	Challenged  Player
+	GameID      int64
+	Name        string
+	Width       int
+	Height      int
+	Ranked      bool
+	Handicap    int
+	Rules       RuleSet
+	Color       string // Color of the challenger
+	TimeControl TimeControl
+}
+
+// SeekGraphEntry is an open challenge in the seek graph, or a removal of one.
+type SeekGraphEntry struct {
+	ChallengeID int64 `json:"challenge_id"`
+	GameID      int64 `json:"game_id"`
+	UserID      int64 `json:"user_id"`
+	Username    string
+	Ranking     float32 // Of the challenger
+	MinRank     int     `json:"min_rank"`
+	MaxRank     int     `json:"max_rank"`
+	Ranked      bool
+	Handicap    int
+	Width       int
+	Height      int
+	Rules       RuleSet
+	TimeControl *TimeControl `json:"time_control_parameters"`
+	TimePerMove int          `json:"time_per_move"` // Average seconds per move
+	Delete      Bool01       // Set for a removed challenge
+}
+
+// Removed returns whether the challenge was accepted or withdrawn and should
+// be removed from the seek graph.
+func (e SeekGraphEntry) Removed() bool {
+	return bool(e.Delete)
+}
+
+type NotificationType string
+
+const (
+	NotificationChallenge   NotificationType = "challenge"
+	NotificationGameStarted NotificationType = "gameStarted"
+	NotificationGameEnded   NotificationType = "gameEnded"
+	NotificationYourMove    NotificationType = "yourMove"
+)
+
+// Notification is a pending notification of the logged in user.
+type Notification struct {
+	ID        string
+	Type      NotificationType
+	GameID    int64     `json:"game_id"` // Zero if not about a game
+	Player    Player    `json:"user"`    // Who triggered it, e.g. the challenger
+	Timestamp Timestamp // When it was created
+	Read      bool
+
+	// Data is the raw notification, whose fields vary by Type, for caller
+	// to decode fields not covered above.
+	Data json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes known fields and keeps the raw notification in Data.
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	type notification Notification // Avoid recursion
+	var res notification
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*n = Notification(res)
+	n.Data = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Decode decodes Data into v, a struct with the fields specific to Type.
+func (n *Notification) Decode(v any) error {
+	if len(n.Data) == 0 {
+		return fmt.Errorf("notification %q has no data", n.ID)
+	}
+	return json.Unmarshal(n.Data, v)
+}
+
+// ReportReason is the type of behavior reported via ReportPlayer.
+type ReportReason string
+
+const (
+	ReportStalling    ReportReason = "stalling"
+	ReportSandbagging ReportReason = "sandbagging"
+	ReportAbusive     ReportReason = "harassment"
+	ReportCheating    ReportReason = "ai_use"
+)
+
+// LeaderboardEntry is a ranked player in a leaderboard.
+type LeaderboardEntry struct {
+	Rank      int   // Starting from 1
+	PlayerID  int64 `json:"player_id"`
+	Username  string
+	Rating    float32
+	Deviation float32
+}
+
+// Announcement is a site-wide announcement posted by OGS staff.
+type Announcement struct {
+	ID        int64
+	Type      string // E.g. "system", "tournament"
+	Title     string
+	Body      string    `json:"text"`
+	ExpiresAt Timestamp `json:"expiration"`
+}
+
+// Group contains general information of a group.
+type Group struct {
+	ID          int64
+	Name        string
+	MemberCount int `json:"member_count"`
+	Website     string
+}
+
+// GroupMember is a player in a group.
+type GroupMember struct {
+	Player
+	JoinedAt Timestamp
+}
+
+// LadderPlayer is the standing of a player in a ladder.
+type LadderPlayer struct {
+	UserID                 int64
+	Username               string
+	Ranking                float32
+	IncomingChallengeCount int
+	OutgoingChallengeCount int
+	LadderPosition         int // Starting from 1
+}
+
+// RatingHistory is the rating time series of a player, one entry per rated
+// game.
+type RatingHistory struct {
+	Entries []RatingEntry
+}
+
+type RatingEntry struct {
+	Ended     Timestamp
+	Rating    float32
+	Deviation float32
+	Won       bool
+}
+
+// GameHistoryPage is a page of games played by a player.
+type GameHistoryPage struct {
+	Results  []GameListEntry
+	Total    int    // Number of games across all pages
+	Page     int    // Starting from 1
+	Next     string // URL of the next page, empty on the last page
+	Previous string // URL of the previous page, empty on the first page
+}
+
+// HasNext returns whether there are more games after this page.
+func (p *GameHistoryPage) HasNext() bool {
+	return p.Next != ""
+}
+
 type GameListType string
 
 const (
@@ -866,8 +1587,17 @@ type GameListResponse struct {
 	Results []GameListEntry
 }
 
+// ChatChannel is a chat channel of a game.
+type ChatChannel string
+
+const (
+	ChatMain      ChatChannel = "main"      // Visible to players and spectators
+	ChatMalkovich ChatChannel = "malkovich" // Visible after the game ends
+	ChatSpectator ChatChannel = "spectator" // Visible to spectators only
+)
+
 type GameChat struct {
-	Channel string
+	Channel ChatChannel
 	Line    GameChatLine
 }
 
@@ -876,9 +1606,9 @@ type GameChatLine struct {
 	Body         string
 	Date         Timestamp
 	MoveNumber   int `json:"move_number"`
-	Channel      string
+	Channel      ChatChannel
 	PlayerID     int64 `json:"player_id"`
 	Username     string
-	Professional int // XXX: server response is a number 0/1
+	Professional Bool01
 	Ranking      float32
 }
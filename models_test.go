@@ -2,6 +2,8 @@ package googs
 
 import (
 	"encoding/json"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -79,6 +81,18 @@ func TestTimestamp_UnmarshalJSON(t *testing.T) {
 			want:    time.Time{},
 			wantErr: true,
 		},
+		{
+			name:    "RFC 3339 string",
+			input:   `"2023-01-01T00:00:00Z"`,
+			want:    time.Unix(1672531200, 0),
+			wantErr: false,
+		},
+		{
+			name:    "null",
+			input:   "null",
+			want:    time.Time{},
+			wantErr: false,
+		},
 		{
 			name:    "invalid timestamp (empty string)",
 			input:   `""`,
@@ -231,6 +245,119 @@ func TestNewA1Coordinate(t *testing.T) {
 	}
 }
 
+func TestFormatMoves(t *testing.T) {
+	moves := []Move{
+		{OriginCoordinate: OriginCoordinate{X: 3, Y: 3}},
+		{OriginCoordinate: OriginCoordinate{X: 8, Y: 18}},
+		{OriginCoordinate: OriginCoordinate{X: -1, Y: -1}},
+		{OriginCoordinate: OriginCoordinate{X: 19, Y: 0}},
+	}
+	want := []string{"D16", "J1", "pass", "[19,0]"}
+	if got := FormatMoves(moves, 19); !slices.Equal(got, want) {
+		t.Errorf("FormatMoves() want %q, got %q", want, got)
+	}
+}
+
+func TestGTPVertex(t *testing.T) {
+	for _, tc := range []struct {
+		vertex string
+		want   OriginCoordinate
+	}{
+		{"Q16", OriginCoordinate{X: 15, Y: 3}},
+		{"d4", OriginCoordinate{X: 3, Y: 15}},
+		{"J1", OriginCoordinate{X: 8, Y: 18}},
+		{"PASS", OriginCoordinate{X: -1, Y: -1}},
+	} {
+		got, err := ParseGTPVertex(tc.vertex, 19)
+		if err != nil || *got != tc.want {
+			t.Errorf("ParseGTPVertex(%q) want %s, got %v, %v", tc.vertex, tc.want, got, err)
+		}
+		if want := strings.ToUpper(tc.vertex); strings.ToLower(tc.want.ToGTP(19)) != strings.ToLower(want) {
+			t.Errorf("ToGTP(%s) want %q, got %q", tc.want, want, tc.want.ToGTP(19))
+		}
+	}
+	for _, vertex := range []string{"I5", "T20", "Z1", ""} {
+		if _, err := ParseGTPVertex(vertex, 19); err == nil {
+			t.Errorf("ParseGTPVertex(%q) want error, got nil", vertex)
+		}
+	}
+	if got := (OriginCoordinate{X: 19, Y: 0}).ToGTP(19); got != "" {
+		t.Errorf("ToGTP() out of board want empty, got %q", got)
+	}
+}
+
+func TestSGFVertex(t *testing.T) {
+	for _, tc := range []struct {
+		vertex    string
+		boardSize int
+		want      OriginCoordinate
+		sgf       string // Formatted back
+	}{
+		{"dp", 19, OriginCoordinate{X: 3, Y: 15}, "dp"},
+		{"aa", 9, OriginCoordinate{X: 0, Y: 0}, "aa"},
+		{"..", 19, OriginCoordinate{X: -1, Y: -1}, ".."},
+		{"", 19, OriginCoordinate{X: -1, Y: -1}, ".."},
+		{"tt", 19, OriginCoordinate{X: -1, Y: -1}, ".."},
+		{"tt", 21, OriginCoordinate{X: 19, Y: 19}, "tt"},
+	} {
+		got, err := ParseSGFVertex(tc.vertex, tc.boardSize)
+		if err != nil || *got != tc.want {
+			t.Errorf("ParseSGFVertex(%q, %d) want %s, got %v, %v", tc.vertex, tc.boardSize, tc.want, got, err)
+			continue
+		}
+		if sgf := got.ToSGF(); sgf != tc.sgf {
+			t.Errorf("ToSGF(%s) want %q, got %q", got, tc.sgf, sgf)
+		}
+	}
+	for _, vertex := range []string{"a", "abc", "ja", "A1", "a-"} {
+		if _, err := ParseSGFVertex(vertex, 9); err == nil {
+			t.Errorf("ParseSGFVertex(%q, 9) want error, got nil", vertex)
+		}
+	}
+}
+
+func TestParseSGFVertices(t *testing.T) {
+	got, err := ParseSGFVertices("edhdid", 19)
+	want := []OriginCoordinate{{X: 4, Y: 3}, {X: 7, Y: 3}, {X: 8, Y: 3}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("ParseSGFVertices() want %v, got %v, %v", want, got, err)
+	}
+	if got, err := ParseSGFVertices("", 19); err != nil || len(got) != 0 {
+		t.Errorf("ParseSGFVertices(\"\") want empty, got %v, %v", got, err)
+	}
+	if got := SGFVertices(want); got != "edhdid" {
+		t.Errorf("SGFVertices() want %q, got %q", "edhdid", got)
+	}
+	for _, s := range []string{"edh", "edzz"} {
+		if _, err := ParseSGFVertices(s, 19); err == nil {
+			t.Errorf("ParseSGFVertices(%q) want error, got nil", s)
+		}
+	}
+}
+
+func TestRemovedStones_RemovedCoordinates(t *testing.T) {
+	var r RemovedStones
+	if err := json.Unmarshal([]byte(`{"all_removed": "edhdid", "removed": true, "stones": "id"}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	want := []OriginCoordinate{{X: 4, Y: 3}, {X: 7, Y: 3}, {X: 8, Y: 3}}
+	if got, err := r.RemovedCoordinates(19); err != nil || !slices.Equal(got, want) {
+		t.Errorf("RemovedCoordinates() want %v, got %v, %v", want, got, err)
+	}
+	if _, err := r.RemovedCoordinates(5); err == nil {
+		t.Error("RemovedCoordinates() want error for out of bounds, got nil")
+	}
+
+	accepted := RemovedStonesAccepted{Stones: "edhdi"}
+	if _, err := accepted.RemovedCoordinates(19); err == nil {
+		t.Error("RemovedCoordinates() want error for odd length, got nil")
+	}
+	accepted.Stones = ""
+	if got, err := accepted.RemovedCoordinates(19); err != nil || len(got) != 0 {
+		t.Errorf("RemovedCoordinates() want empty, got %v, %v", got, err)
+	}
+}
+
 func TestA1Coordinate_ToOriginCoordinate(t *testing.T) {
 	for _, tc := range []struct {
 		name      string
@@ -358,3 +485,287 @@ func TestGameState_RemovalString(t *testing.T) {
 		})
 	}
 }
+
+func TestClock_ComputeClock_Paused(t *testing.T) {
+	now := time.Now()
+	clock := Clock{
+		BlackPlayerID:   1,
+		WhitePlayerID:   2,
+		CurrentPlayerID: 1,
+		BlackTime:       PlayerTime{ThinkingTime: 600},
+		WhiteTime:       PlayerTime{ThinkingTime: 600},
+		LastMove:        Timestamp{now.Add(-time.Hour)},
+		PausedSince:     Timestamp{now.Add(-time.Hour + 100*time.Second)},
+	}
+	tc := &TimeControl{System: ClockAbsolute}
+
+	// Only 100s elapsed before the pause
+	if got := clock.ComputeClock(tc, PlayerBlack); got.MainTime < 499 || got.MainTime > 501 {
+		t.Errorf("ComputeClock() of paused game want 500s, got %+v", got)
+	}
+	if got := clock.ComputeClock(tc, PlayerWhite); got.MainTime != 600 {
+		t.Errorf("ComputeClock() of waiting player want 600s, got %+v", got)
+	}
+
+	// Paused state carried by the pause object only
+	clock.Pause = &GamePauseState{Paused: true, PausedSince: clock.PausedSince}
+	clock.PausedSince = Timestamp{}
+	if got := clock.ComputeClock(tc, PlayerBlack); got.MainTime < 499 || got.MainTime > 501 {
+		t.Errorf("ComputeClock() of game paused via Pause want 500s, got %+v", got)
+	}
+
+	clock.Pause = nil
+	if got := clock.ComputeClock(tc, PlayerBlack); !got.TimedOut {
+		t.Errorf("ComputeClock() of resumed game want timed out, got %+v", got)
+	}
+}
+
+func TestClock_ComputeClockAt(t *testing.T) {
+	lastMove := time.Unix(1700000000, 0)
+	for _, tc := range []struct {
+		name    string
+		tc      TimeControl
+		time    PlayerTime
+		elapsed time.Duration
+		want    ComputedClock
+	}{
+		{"fischer", TimeControl{System: ClockFischer, TimeIncrement: 5}, PlayerTime{ThinkingTime: 60}, 15 * time.Second,
+			ComputedClock{System: ClockFischer, MainTime: 45, Increment: 5}},
+		{"fischer capped", TimeControl{System: ClockFischer, TimeIncrement: 5, MaxTime: 50}, PlayerTime{ThinkingTime: 60}, 15 * time.Second,
+			ComputedClock{System: ClockFischer, MainTime: 35, Increment: 5}},
+		{"fischer sudden death", TimeControl{System: ClockFischer}, PlayerTime{ThinkingTime: 60}, 55 * time.Second,
+			ComputedClock{System: ClockFischer, MainTime: 5, SuddenDeath: true}},
+		{"absolute timeout", TimeControl{System: ClockAbsolute}, PlayerTime{ThinkingTime: 60}, 61 * time.Second,
+			ComputedClock{System: ClockAbsolute, SuddenDeath: true, TimedOut: true}},
+		{"byoyomi main time", TimeControl{System: ClockByoyomi, PeriodTime: 30}, PlayerTime{ThinkingTime: 60, Periods: 3, PeriodTime: 30}, 20 * time.Second,
+			ComputedClock{System: ClockByoyomi, MainTime: 40, PeriodsLeft: 3, PeriodTimeLeft: 30}},
+		{"byoyomi period used", TimeControl{System: ClockByoyomi, PeriodTime: 30}, PlayerTime{ThinkingTime: 60, Periods: 3, PeriodTime: 30}, 125 * time.Second,
+			ComputedClock{System: ClockByoyomi, PeriodsLeft: 1, PeriodTimeLeft: 25, SuddenDeath: true}},
+		{"byoyomi in overtime", TimeControl{System: ClockByoyomi, PeriodTime: 30}, PlayerTime{Periods: 3, PeriodTime: 30}, 10 * time.Second,
+			ComputedClock{System: ClockByoyomi, PeriodsLeft: 3, PeriodTimeLeft: 20}},
+		{"byoyomi timeout", TimeControl{System: ClockByoyomi, PeriodTime: 30}, PlayerTime{ThinkingTime: 60, Periods: 3, PeriodTime: 30}, 155 * time.Second,
+			ComputedClock{System: ClockByoyomi, SuddenDeath: true, TimedOut: true}},
+		{"canadian block", TimeControl{System: ClockCanadian}, PlayerTime{ThinkingTime: 10, MovesLeft: 5, BlockTime: 100}, 40 * time.Second,
+			ComputedClock{System: ClockCanadian, MovesLeft: 5, BlockTimeLeft: 70}},
+		{"canadian sudden death", TimeControl{System: ClockCanadian}, PlayerTime{MovesLeft: 1, BlockTime: 100}, 40 * time.Second,
+			ComputedClock{System: ClockCanadian, MovesLeft: 1, BlockTimeLeft: 60, SuddenDeath: true}},
+		{"canadian timeout", TimeControl{System: ClockCanadian}, PlayerTime{ThinkingTime: 10, MovesLeft: 5, BlockTime: 100}, 111 * time.Second,
+			ComputedClock{System: ClockCanadian, MovesLeft: 5, SuddenDeath: true, TimedOut: true}},
+		{"simple", TimeControl{System: ClockSimple, PerMove: 30}, PlayerTime{}, 12 * time.Second,
+			ComputedClock{System: ClockSimple, MainTime: 18}},
+		{"simple timeout", TimeControl{System: ClockSimple, PerMove: 30}, PlayerTime{}, 31 * time.Second,
+			ComputedClock{System: ClockSimple, SuddenDeath: true, TimedOut: true}},
+		{"none", TimeControl{System: ClockNone}, PlayerTime{}, time.Hour,
+			ComputedClock{System: ClockNone}},
+	} {
+		clock := &Clock{
+			BlackPlayerID:   1,
+			WhitePlayerID:   2,
+			CurrentPlayerID: 1,
+			BlackTime:       tc.time,
+			WhiteTime:       tc.time,
+			LastMove:        Timestamp{lastMove},
+		}
+		if got := clock.ComputeClockAt(&tc.tc, PlayerBlack, lastMove.Add(tc.elapsed)); *got != tc.want {
+			t.Errorf("%s: ComputeClockAt() want %+v, got %+v", tc.name, tc.want, *got)
+		}
+		// Not in turn, nothing elapsed
+		if got := clock.ComputeClockAt(&tc.tc, PlayerWhite, lastMove.Add(tc.elapsed)); got.TimedOut && tc.time.ThinkingTime > 0 {
+			t.Errorf("%s: ComputeClockAt() of waiting player want not timed out, got %+v", tc.name, *got)
+		}
+	}
+}
+
+func TestComputedClock_String_Fischer(t *testing.T) {
+	for _, tc := range []struct {
+		clock ComputedClock
+		want  string
+	}{
+		{ComputedClock{System: ClockFischer, MainTime: 125, Increment: 5}, "2:05 +5s"},
+		{ComputedClock{System: ClockFischer, MainTime: 125}, "2:05"},
+		{ComputedClock{System: ClockFischer, MainTime: 8, Increment: 30, SuddenDeath: true}, "8s +30s (SD)"},
+	} {
+		if got := tc.clock.String(); got != tc.want {
+			t.Errorf("String() want %q, got %q", tc.want, got)
+		}
+	}
+}
+
+func TestGame_Rengo(t *testing.T) {
+	var g Game
+	// Black team 1, 3 and white team 2, 4, member 3 to move
+	payload := `{"rengo": true, "time_control": {"system": "absolute"},
+		"players": {"black": {"id": 1}, "white": {"id": 2}},
+		"rengo_teams": {"black": [{"id": 1}, {"id": 3}], "white": [{"id": 2}, {"id": 4}]},
+		"clock": {"black_player_id": 1, "white_player_id": 2, "current_player": 3,
+			"black_time": {"thinking_time": 100}, "white_time": {"thinking_time": 200}}}`
+	if err := json.Unmarshal([]byte(payload), &g); err != nil {
+		t.Fatal(err)
+	}
+	black, white := g.RengoTeams()
+	if len(black) != 2 || black[1].ID != 3 || len(white) != 2 || white[1].ID != 4 {
+		t.Errorf("RengoTeams() got %v, %v", black, white)
+	}
+	for id, want := range map[int64]PlayerColor{1: PlayerBlack, 3: PlayerBlack, 4: PlayerWhite, 5: PlayerUnknown} {
+		if got := g.PlayerColor(id); got != want {
+			t.Errorf("PlayerColor(%d) want %v, got %v", id, want, got)
+		}
+	}
+	if got := g.Opponent(3); got.ID != 2 {
+		t.Errorf("Opponent(3) want 2, got %d", got.ID)
+	}
+	if got := g.WhoseTurn(&GameState{PlayerToMove: 3}); got != PlayerBlack {
+		t.Errorf("WhoseTurn() want black, got %v", got)
+	}
+
+	g.Clock.LastMove = Timestamp{time.Now().Add(-10 * time.Second)}
+	if got := g.ComputeClock(PlayerBlack); got.MainTime < 89 || got.MainTime > 91 {
+		t.Errorf("ComputeClock(black) want team clock running at 90s, got %+v", got)
+	}
+	if got := g.ComputeClock(PlayerWhite); got.MainTime != 200 {
+		t.Errorf("ComputeClock(white) want 200s, got %+v", got)
+	}
+}
+
+func TestClock_ComputeClock_Drift(t *testing.T) {
+	clock := Clock{
+		BlackPlayerID:   1,
+		CurrentPlayerID: 1,
+		BlackTime:       PlayerTime{ThinkingTime: 600},
+		LastMove:        Timestamp{time.Now()},
+		Drift:           100 * time.Second, // Local clock is ahead
+	}
+	tc := &TimeControl{System: ClockAbsolute}
+	if got := clock.ComputeClock(tc, PlayerBlack); got.MainTime < 599 {
+		t.Errorf("ComputeClock() want 600s without elapsed time in OGS clock, got %+v", got)
+	}
+	clock.LastMove = Timestamp{time.Now().Add(-200 * time.Second)}
+	if got := clock.ComputeClock(tc, PlayerBlack); got.MainTime < 499 || got.MainTime > 501 {
+		t.Errorf("ComputeClock() want 500s with drift applied, got %+v", got)
+	}
+}
+
+func TestGamePhase(t *testing.T) {
+	for _, tc := range []struct {
+		input        string
+		wantValid    bool
+		wantTerminal bool
+	}{
+		{`"play"`, true, false},
+		{`"stone removal"`, true, false},
+		{`"finished"`, true, true},
+		{`"Finished"`, false, false},
+		{`""`, false, false},
+	} {
+		var p GamePhase
+		if err := json.Unmarshal([]byte(tc.input), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Valid() != tc.wantValid || p.IsTerminal() != tc.wantTerminal {
+			t.Errorf("GamePhase(%s) want valid %v terminal %v, got %v %v", tc.input, tc.wantValid, tc.wantTerminal, p.Valid(), p.IsTerminal())
+		}
+	}
+}
+
+func TestTimeControl_UnmarshalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    ClockSystem
+		wantErr bool
+	}{
+		{`{"system": "byoyomi", "main_time": 600, "period_time": 30, "periods": 5}`, ClockByoyomi, false},
+		{`{"system": "fischer", "initial_time": 300, "time_increment": 10}`, ClockFischer, false},
+		{`{"system": "none"}`, ClockNone, false},
+		{`null`, "", false},
+		{`{"system": "hourglass"}`, "hourglass", false},
+		{`{"system": "unknown"}`, ClockUnknown, false},
+		{`{}`, "", false},
+	} {
+		var got TimeControl
+		err := json.Unmarshal([]byte(tc.input), &got)
+		if (err != nil) != tc.wantErr || got.System != tc.want {
+			t.Errorf("Unmarshal(%s) want %q (error %v), got %q, %v", tc.input, tc.want, tc.wantErr, got.System, err)
+		}
+	}
+}
+
+func TestGame_UnmarshalJSON_GroupIDs(t *testing.T) {
+	var g Game
+	if err := json.Unmarshal([]byte(`{"width": 19, "group_ids": [1, "2", "ladder"]}`), &g); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(g.GroupIDs, []int64{1, 2}) || !slices.Equal(g.GroupNames, []string{"ladder"}) || g.Width != 19 {
+		t.Errorf("Unmarshal got GroupIDs %v, GroupNames %v, Width %d", g.GroupIDs, g.GroupNames, g.Width)
+	}
+}
+
+func TestOverview_UnmarshalJSON(t *testing.T) {
+	var v Overview
+	data := `{"active_games": [{"id": 42, "width": 19, "height": 19, "json": {"game_id": 42, "game_name": "Friendly Match",
+		"width": 19, "height": 19, "moves": [[3, 3, 1000], [15, 15, 2000]], "group_ids": ["7"]}}]}`
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.ActiveGames) != 1 {
+		t.Fatalf("Unmarshal want 1 active game, got %d", len(v.ActiveGames))
+	}
+	g := v.ActiveGames[0]
+	if g.GameID != 42 || g.GameName != "Friendly Match" || len(g.Moves) != 2 || !slices.Equal(g.GroupIDs, []int64{7}) {
+		t.Errorf("Unmarshal got unexpected game %+v", g.Game)
+	}
+}
+
+func TestTimeControl_Speed(t *testing.T) {
+	var tc TimeControl
+	if err := json.Unmarshal([]byte(`{"system": "fischer", "speed": "correspondence"}`), &tc); err != nil {
+		t.Fatal(err)
+	}
+	if tc.Speed != SpeedCorrespondence || !tc.IsCorrespondence() || tc.IsLive() || tc.IsBlitz() {
+		t.Errorf("Unmarshal got speed %q", tc.Speed)
+	}
+}
+
+func TestBool01_UnmarshalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    Bool01
+		wantErr bool
+	}{
+		{"0", false, false},
+		{"1", true, false},
+		{"false", false, false},
+		{"true", true, false},
+		{"null", false, false},
+		{`"1"`, false, true},
+	} {
+		var got Bool01
+		err := json.Unmarshal([]byte(tc.input), &got)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("Unmarshal(%s) want %v (error %v), got %v, %v", tc.input, tc.want, tc.wantErr, got, err)
+		}
+	}
+}
+
+func TestRuleSet(t *testing.T) {
+	for _, tc := range []struct {
+		rules          RuleSet
+		wantPrisoners  bool
+		wantAreaScored bool
+	}{
+		{RulesJapanese, true, false},
+		{RulesKorean, true, false},
+		{RulesChinese, false, true},
+		{RulesAGA, false, true},
+		{RulesNewZealand, false, true},
+		{RulesIng, false, true},
+		{"unknown", false, false},
+	} {
+		g := &Game{Rules: tc.rules}
+		if got := g.RuleSet().ScoresPrisoners(); got != tc.wantPrisoners {
+			t.Errorf("%q ScoresPrisoners() want %v, got %v", tc.rules, tc.wantPrisoners, got)
+		}
+		if got := g.RuleSet().UsesAreaScoring(); got != tc.wantAreaScored {
+			t.Errorf("%q UsesAreaScoring() want %v, got %v", tc.rules, tc.wantAreaScored, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package googs
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ClientOption configures a Client created by NewClient or LoadClient.
+type ClientOption func(*Client)
+
+// WithHTTPTimeout sets timeout of REST requests.
+func WithHTTPTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.ownHTTPClient().Timeout = d
+	}
+}
+
+// WithHTTPTransport sets the transport of REST requests, e.g. to use a proxy
+// or to stub the server in tests.
+func WithHTTPTransport(t http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.ownHTTPClient().Transport = t
+	}
+}
+
+// WithBaseURL sets the OGS server to send REST requests to, e.g.
+// "https://beta.online-go.com".
+func WithBaseURL(u string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = u
+	}
+}
+
+// WithRealtimeURL sets the OGS websocket endpoint, e.g.
+// "wss://beta.online-go.com/socket.io/?transport=websocket&EIO=3".
+func WithRealtimeURL(u string) ClientOption {
+	return func(c *Client) {
+		c.realtimeURL = u
+	}
+}
+
+// WithMaxRetries sets Client.MaxRetries.
+func WithMaxRetries(n int) ClientOption {
+	return func(c *Client) {
+		c.MaxRetries = n
+	}
+}
+
+// WithReconnectPolicy overrides the default reconnect policy, see
+// SetReconnectPolicy. Use WithReconnectPolicy(0, 0, 0) to disable automatic
+// reconnection.
+func WithReconnectPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.SetReconnectPolicy(maxAttempts, baseDelay, maxDelay)
+	}
+}
+
+// WithLatencyReport measures latency via Ping every interval and reports it
+// to OGS for every connected game, so the opponent's clock compensates for
+// it like for the web client. Disabled by default or when interval is zero.
+func WithLatencyReport(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.latencyInterval = interval
+	}
+}
+
+// WithLogger sets a logger for debugging, nothing is logged by default. REST
+// requests, websocket connection changes, emitted and received events are
+// logged at debug level with credentials redacted.
+func WithLogger(l *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// ownHTTPClient returns HTTPClient, allocates one with default settings if
+// not set yet so options don't modify the shared default client.
+func (c *Client) ownHTTPClient() *http.Client {
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: defaultHTTPClient.Timeout}
+	}
+	return c.HTTPClient
+}
@@ -0,0 +1,75 @@
+package googs
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket allowing rate requests per second on average
+// with bursts up to burst requests.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	burst = cond(burst < 1, 1, burst)
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a token is available. A token is reserved immediately so
+// concurrent callers are spaced out rather than woken up at the same time.
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// SetRateLimit limits REST requests to rps per second on average with bursts
+// up to burst requests, requests block until allowed. A request still
+// rejected with 429 is retried once after the Retry-After delay, even when
+// MaxRetries is zero. A non-positive rps removes the limit (the default).
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limiter = cond(rps > 0, newRateLimiter(rps, burst), nil)
+}
+
+func (c *Client) rateLimiter() *rateLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limiter
+}
+
+// maxRetryDelay is the longest delay before retrying a REST request.
+const maxRetryDelay = time.Minute
+
+// retryAfter parses the Retry-After header given in either seconds or an HTTP
+// date, defaults to fallback when absent or invalid.
+func retryAfter(h http.Header, fallback time.Duration) time.Duration {
+	v := h.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return fallback
+}
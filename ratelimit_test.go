@@ -0,0 +1,128 @@
+package googs
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_SetRateLimit(t *testing.T) {
+	var times []time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+	})
+	c := newTestClient(t, mux)
+	c.SetRateLimit(20, 2)
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.AboutMe(); err != nil {
+			t.Fatalf("AboutMe() want no error, got %v", err)
+		}
+	}
+	// The first 2 requests are a burst, the remaining are spaced by 50ms.
+	if got, want := times[4].Sub(times[0]), 140*time.Millisecond; got < want {
+		t.Errorf("5 requests at 20 rps with burst 2 want at least %v, got %v", want, got)
+	}
+}
+
+func TestClient_TooManyRequests(t *testing.T) {
+	var times []time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.FormValue("grant_type") != "refresh_token" {
+			t.Errorf("retried request want form data, got %v", r.Form)
+		}
+		writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{})
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "old"
+	c.SetRateLimit(100, 1) // Retries a 429 once without MaxRetries
+
+	if err := c.refreshToken(); err != nil {
+		t.Fatalf("refreshToken() want no error after retry, got %v", err)
+	}
+	if len(times) != 2 || times[1].Sub(times[0]) < time.Second {
+		t.Errorf("want 1 retry after 1s, got %d requests %v", len(times), times)
+	}
+}
+
+func TestClient_TooManyRequests_LongRetryAfter(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c := newTestClient(t, mux)
+	c.MaxRetries = 3
+
+	start := time.Now()
+	_, err := c.AboutMe()
+	if requests != 1 || statusCode(err) != http.StatusTooManyRequests || time.Since(start) > maxRetryDelay {
+		t.Errorf("want 429 returned without retry, got %d requests, %v after %v", requests, err, time.Since(start))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{"3", 3 * time.Second},
+		{"", 2 * time.Second},
+		{"soon", 2 * time.Second},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0}, // In the past
+	} {
+		h := http.Header{}
+		h.Set("Retry-After", tc.value)
+		if got := retryAfter(h, 2*time.Second); got != tc.want {
+			t.Errorf("retryAfter(%q) want %v, got %v", tc.value, tc.want, got)
+		}
+	}
+}
+
+func TestClient_MaxRetries(t *testing.T) {
+	if c := NewClient("id", "secret"); c.MaxRetries != 0 {
+		t.Errorf("NewClient() want no retries by default, got %d", c.MaxRetries)
+	}
+	for _, tc := range []struct {
+		maxRetries   int
+		wantRequests int
+		wantStatus   int
+	}{
+		{0, 1, http.StatusServiceUnavailable},
+		{2, 3, http.StatusServiceUnavailable},
+		{5, 4, 0}, // Succeeded on the 4th request
+	} {
+		requests := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests < 4 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+		})
+		c := newTestClient(t, mux)
+		c.MaxRetries = tc.maxRetries
+
+		_, err := c.AboutMe()
+		if requests != tc.wantRequests || statusCode(err) != tc.wantStatus {
+			t.Errorf("MaxRetries %d want %d requests and status %d, got %d, %v", tc.maxRetries, tc.wantRequests, tc.wantStatus, requests, err)
+		}
+	}
+}
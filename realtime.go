@@ -1,8 +1,16 @@
 package googs
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	socketio "github.com/graarh/golang-socketio"
@@ -18,111 +26,1205 @@ const (
 	realtimeURL = "wss://online-go.com/socket.io/?transport=websocket&EIO=3"
 )
 
+// socket is the subset of *socketio.Client used by Client, so tests can
+// substitute a fake connection.
+type socket interface {
+	Emit(event string, args any) error
+	On(event string, fn any) error
+	Ack(event string, args any, timeout time.Duration) (string, error)
+	Close()
+}
+
+// ConnState is the state of the websocket connection.
+type ConnState int
+
+const (
+	Disconnected ConnState = iota
+	Connected
+	Reconnecting
+)
+
+func (s ConnState) String() string {
+	return [...]string{"Disconnected", "Connected", "Reconnecting"}[s]
+}
+
+// OnConnectionState registers a callback invoked on websocket connection state
+// changes: Connected once connected and authenticated (including the initial
+// connection), Disconnected when the connection is lost, and Reconnecting
+// before every reconnect attempt.
+func (c *Client) OnConnectionState(fn func(state ConnState)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onConnState = fn
+}
+
+func (c *Client) setConnState(state ConnState) {
+	c.mu.Lock()
+	fn := c.onConnState
+	c.mu.Unlock()
+
+	c.debug("Websocket state changed", "state", state)
+	if fn != nil {
+		fn(state)
+	}
+}
+
 // This is automatically called when Client is authenticated.
 func (c *Client) connect() error {
-	conn, err := socketio.Dial(realtimeURL, transport.GetDefaultWebsocketTransport())
+	c.mu.Lock()
+	c.closed = false
+	c.mu.Unlock()
+	return c.dialSocket()
+}
+
+// dialSocket dials the websocket and restores event handlers and games. The
+// new socket is closed right away when Disconnect() was called meanwhile,
+// e.g. while reconnecting.
+func (c *Client) dialSocket() error {
+	dial := c.dial
+	if dial == nil {
+		dial = func() (socket, error) {
+			u := cond(c.realtimeURL != "", c.realtimeURL, realtimeURL)
+			return socketio.Dial(u, transport.GetDefaultWebsocketTransport())
+		}
+	}
+	conn, err := dial()
 	if err != nil {
+		c.debug("Websocket connect failed", "error", err)
 		return err
 	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		conn.Close()
+		return errDisconnected
+	}
 	c.socket = conn
+	var events []string
+	for event := range c.handlers {
+		events = append(events, event)
+	}
+	for event := range c.buffered {
+		if _, ok := c.handlers[event]; !ok {
+			events = append(events, event)
+		}
+	}
+	games := make(map[int64]map[string]any, len(c.games))
+	for gameID, payload := range c.games {
+		games[gameID] = payload
+	}
+	seekGraph := c.seekGraph
+	startReporter := c.latencyInterval > 0 && !c.reportingLatency
+	c.reportingLatency = c.reportingLatency || startReporter
+	c.mu.Unlock()
 
-	// Authenticate with user_jwt. The `chat/connect`, `incident/connect`,
-	// and `notification/connect` messages have been removed and are an
-	// implicitly called by the `authenticate` message.
-	if err := c.socket.Emit("authenticate", map[string]any{
-		"jwt": c.UserJWT,
+	if err := conn.On(socketio.OnDisconnection, func(_ any) {
+		c.setConnState(Disconnected)
+		go c.reconnect(conn)
 	}); err != nil {
 		return err
 	}
-	return err
+
+	// Authenticate with user_jwt. The `chat/connect`, `incident/connect`,
+	// and `notification/connect` messages have been removed and are an
+	// implicitly called by the `authenticate` message. Anonymous clients
+	// skip it and can only watch games.
+	if c.UserJWT != "" {
+		c.debug("Emit", "event", "authenticate")
+		if err := conn.Emit("authenticate", map[string]any{
+			"jwt": c.UserJWT,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Restore event handlers and game connections after a reconnect.
+	for _, event := range events {
+		if err := conn.On(event, c.dispatch(event)); err != nil {
+			return err
+		}
+	}
+	for _, payload := range games {
+		c.debug("Emit", "event", "game/connect", "data", payload)
+		if err := conn.Emit("game/connect", payload); err != nil {
+			return err
+		}
+	}
+	if seekGraph {
+		c.debug("Emit", "event", "seek_graph/connect", "data", seekGraphPayload)
+		if err := conn.Emit("seek_graph/connect", seekGraphPayload); err != nil {
+			return err
+		}
+	}
+	c.setConnState(Connected)
+	if startReporter {
+		ticker := time.NewTicker(c.latencyInterval)
+		go func() {
+			defer ticker.Stop()
+			c.reportLatency(ticker.C)
+		}()
+	}
+	return nil
+}
+
+// reportLatency pings and reports the latency to OGS for every connected game
+// on every tick, until the Client is disconnected.
+func (c *Client) reportLatency(tick <-chan time.Time) {
+	for range tick {
+		c.mu.Lock()
+		closed := c.closed
+		var gameIDs []int64
+		for gameID := range c.games {
+			gameIDs = append(gameIDs, gameID)
+		}
+		c.mu.Unlock()
+		if closed {
+			c.mu.Lock()
+			c.reportingLatency = false
+			c.mu.Unlock()
+			return
+		}
+		if len(gameIDs) == 0 {
+			continue
+		}
+
+		latency, err := c.Ping()
+		if err != nil {
+			c.debug("Ping failed", "error", err)
+			continue
+		}
+		for _, gameID := range gameIDs {
+			if err := c.emit(context.Background(), "game/latency", map[string]any{
+				"game_id": gameID,
+				"latency": latency.Milliseconds(),
+			}); err != nil {
+				c.debug("Failed to report latency", "game", gameID, "error", err)
+			}
+		}
+	}
 }
 
 func (c *Client) Disconnect() {
-	if c.socket != nil {
-		c.socket.Close()
+	c.mu.Lock()
+	c.closed = true
+	if c.keepalive != nil {
+		close(c.keepalive)
+		c.keepalive = nil
+	}
+	conn := c.socket
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// SetReconnectPolicy configures automatic reconnection when the websocket is
+// dropped by the server. Up to maxAttempts reconnections are attempted (no
+// limit if negative, disabled if zero) with exponential backoff starting from
+// baseDelay and capped at maxDelay. Clients created by constructors reconnect
+// without limit by default. Event handlers registered via On...
+// functions and games connected via GameConnect are restored on reconnect.
+func (c *Client) SetReconnectPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxAttempts = maxAttempts
+	c.baseDelay = baseDelay
+	c.maxDelay = maxDelay
+}
+
+// OnReconnect registers a callback invoked before every reconnect attempt,
+// starting from 1. Call Disconnect() from the callback to give up.
+func (c *Client) OnReconnect(fn func(attempt int)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReconnect = fn
+}
+
+// reconnect redials until connected or the reconnect policy is exhausted,
+// lost is the socket that has been disconnected.
+func (c *Client) reconnect(lost socket) {
+	c.mu.Lock()
+	if c.closed || c.reconnecting || c.socket != lost || c.maxAttempts == 0 {
+		c.mu.Unlock()
+		return
+	}
+	c.reconnecting = true
+	maxAttempts, delay, maxDelay, onReconnect := c.maxAttempts, c.baseDelay, c.maxDelay, c.onReconnect
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.reconnecting = false
+		c.mu.Unlock()
+	}()
+
+	for attempt := 1; maxAttempts < 0 || attempt <= maxAttempts; attempt++ {
+		c.setConnState(Reconnecting)
+		if onReconnect != nil {
+			onReconnect(attempt)
+		}
+		time.Sleep(delay)
+
+		c.mu.Lock()
+		closed := c.closed
+		c.mu.Unlock()
+		if closed {
+			return
+		}
+		if err := c.dialSocket(); err == nil || errors.Is(err, errDisconnected) {
+			return
+		}
+		delay = cond(delay*2 < maxDelay, delay*2, maxDelay)
+	}
+}
+
+func (c *Client) sock() socket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.socket
+}
+
+// handler is an event callback of type func(any, T), the payload is decoded
+// into T the same way as socketio does. Callbacks registered via On() omit the
+// first parameter, i.e. func(T) or func().
+type handler struct {
+	fn      any
+	removed bool // Guarded by Client.mu
+}
+
+// Subscription is an event handler registered via Subscribe.
+type Subscription struct {
+	c     *Client
+	event string
+	h     *handler
+}
+
+// Cancel removes the handler, other handlers of the same event are kept. It
+// is safe to call more than once.
+func (s *Subscription) Cancel() {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	s.h.removed = true
+	handlers := s.c.handlers[s.event]
+	if i := slices.Index(handlers, s.h); i >= 0 {
+		s.c.handlers[s.event] = slices.Delete(handlers, i, i+1)
+	}
+}
+
+// on registers an event handler in addition to existing ones, handlers are
+// kept to survive reconnects.
+func (c *Client) on(event string, fn any) error {
+	_, _, err := c.subscribe(event, fn)
+	return err
+}
+
+// subscribe registers an event handler, the socket calls a single dispatcher
+// per event which fans out to all handlers. The latest payload of a buffered
+// event received before is returned, the handler is called for later ones
+// only.
+func (c *Client) subscribe(event string, fn any) (*Subscription, json.RawMessage, error) {
+	c.mu.Lock()
+	if c.handlers == nil {
+		c.handlers = make(map[string][]*handler)
+	}
+	h := &handler{fn: fn}
+	handlers := c.handlers[event]
+	c.handlers[event] = append(handlers, h)
+	buffered := c.buffered[event]
+	conn := c.socket
+	c.mu.Unlock()
+
+	sub := &Subscription{c: c, event: event, h: h}
+	if len(handlers) > 0 {
+		return sub, buffered, nil // Dispatcher registered already
+	}
+	if err := conn.On(event, c.dispatch(event)); err != nil {
+		sub.Cancel()
+		return nil, nil, err
+	}
+	return sub, buffered, nil
+}
+
+// buffer keeps the latest payload of the event for handlers registered later,
+// see subscribe.
+func (c *Client) buffer(event string) error {
+	c.mu.Lock()
+	if c.buffered == nil {
+		c.buffered = make(map[string]json.RawMessage)
+	}
+	_, buffering := c.buffered[event]
+	if !buffering {
+		c.buffered[event] = nil
+	}
+	conn := c.socket
+	c.mu.Unlock()
+
+	if buffering {
+		return nil
+	}
+	return conn.On(event, c.dispatch(event))
+}
+
+// dispatch returns the socket callback of the event, which calls all handlers
+// registered for the event in order. A panicking handler is logged and does
+// not stop the others.
+func (c *Client) dispatch(event string) func(any, json.RawMessage) {
+	return func(_ any, data json.RawMessage) {
+		c.debug("Received", "event", event)
+		c.mu.Lock()
+		handlers := append([]*handler(nil), c.handlers[event]...)
+		if _, ok := c.buffered[event]; ok {
+			c.buffered[event] = data
+			c.trackMove(event, data)
+		}
+		c.mu.Unlock()
+
+		for _, h := range handlers {
+			c.mu.Lock()
+			removed := h.removed
+			c.mu.Unlock()
+			if removed {
+				continue // By Off() while dispatching
+			}
+
+			f := reflect.ValueOf(h.fn)
+			var args []reflect.Value
+			if n := f.Type().NumIn(); n > 0 {
+				arg := reflect.New(f.Type().In(n - 1))
+				if err := json.Unmarshal(data, arg.Interface()); err != nil {
+					c.warn("Failed to decode event", "event", event, "error", err)
+					continue
+				}
+				if n == 2 {
+					args = append(args, reflect.Zero(f.Type().In(0)))
+				}
+				args = append(args, arg.Elem())
+			}
+			c.call(event, f, args)
+		}
+	}
+}
+
+// trackMove records the last move number of a connected game from its
+// gamedata and move events, c.mu must be held.
+func (c *Client) trackMove(event string, data json.RawMessage) {
+	var gameID int64
+	var kind string
+	if !strings.HasPrefix(event, "game/") {
+		return
+	}
+	if _, err := fmt.Sscanf(event, "game/%d/%s", &gameID, &kind); err != nil {
+		return
+	}
+	if c.lastMoves == nil {
+		c.lastMoves = make(map[int64]int)
+	}
+	switch kind {
+	case "gamedata":
+		var g struct{ Moves []json.RawMessage }
+		if err := json.Unmarshal(data, &g); err == nil {
+			c.lastMoves[gameID] = len(g.Moves)
+		}
+	case "move":
+		var m struct {
+			MoveNumber int `json:"move_number"`
+		}
+		if err := json.Unmarshal(data, &m); err == nil {
+			c.lastMoves[gameID] = m.MoveNumber
+		}
+	}
+}
+
+func (c *Client) call(event string, f reflect.Value, args []reflect.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.log(slog.LevelError, "Event handler panicked", "event", event, "panic", r)
+		}
+	}()
+	f.Call(args)
+}
+
+// Off stops watching the event, all handlers registered for the event via On
+// or On... functions are removed and never called again.
+func (c *Client) Off(event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.off(event)
+}
+
+// OffGame stops watching all events of the game, e.g. after GameDisconnect.
+func (c *Client) OffGame(gameID int64) {
+	prefix := fmt.Sprintf("game/%d/", gameID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for event := range c.handlers {
+		if strings.HasPrefix(event, prefix) {
+			c.off(event)
+		}
+	}
+}
+
+// OffGameContext calls OffGame once ctx is done, so handlers registered via
+// On... functions of the game until then are called no longer than ctx lives.
+func (c *Client) OffGameContext(ctx context.Context, gameID int64) {
+	context.AfterFunc(ctx, func() { c.OffGame(gameID) })
+}
+
+// off removes handlers of the event, c.mu must be held. The socket keeps
+// the dispatcher which has nothing to call.
+func (c *Client) off(event string) {
+	for _, h := range c.handlers[event] {
+		h.removed = true
+	}
+	delete(c.handlers, event)
+}
+
+// Emit sends an arbitrary event to the server, for events not wrapped by the
+// package yet.
+func (c *Client) Emit(event string, data any) error {
+	return c.emit(context.Background(), event, data)
+}
+
+// On starts watching an arbitrary event, for events not wrapped by the
+// package yet. fn must be a func(T) where the event payload is decoded into T
+// (e.g. json.RawMessage to decode it later), or a func() to ignore the
+// payload. Unlike the underlying socketio callbacks, fn takes no channel
+// parameter. All registered fns are called on the event, use Subscribe to
+// remove one alone later.
+func (c *Client) On(event string, fn any) error {
+	_, err := c.Subscribe(event, fn)
+	return err
+}
+
+// Subscribe is like On but returns the Subscription to cancel the handler.
+func (c *Client) Subscribe(event string, fn any) (*Subscription, error) {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() > 1 || t.NumOut() > 0 {
+		return nil, fmt.Errorf("Subscribe(%q): fn must be a func(T) or func(), got %T", event, fn)
+	}
+	sub, _, err := c.subscribe(event, fn)
+	return sub, err
+}
+
+// SubscribeContext is like Subscribe but cancels the Subscription once ctx is
+// done, e.g. to watch an event no longer than a request is served.
+func (c *Client) SubscribeContext(ctx context.Context, event string, fn any) (*Subscription, error) {
+	sub, err := c.Subscribe(event, fn)
+	if err != nil {
+		return nil, err
+	}
+	context.AfterFunc(ctx, sub.Cancel)
+	return sub, nil
+}
+
+// Ack sends an arbitrary event and waits up to timeout for the server to
+// acknowledge it, the raw JSON response is returned to unmarshal by caller.
+func (c *Client) Ack(event string, data any, timeout time.Duration) (json.RawMessage, error) {
+	c.debug("Emit", "event", event, "data", redacted(data))
+	conn := c.sock()
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	res, err := conn.Ack(event, data, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), nil
+}
+
+// emit sends an event to the server, giving up when ctx is done before the
+// underlying socket accepts the message.
+func (c *Client) emit(ctx context.Context, event string, data any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.debug("Emit", "event", event, "data", redacted(data))
+	conn := c.sock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- conn.Emit(event, data) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // GameConnect connects to a game, client should call On... functions to start
-// watching events.
+// watching events. An anonymous Client connects as an observer.
 func (c *Client) GameConnect(gameID int64) error {
-	return c.socket.Emit("game/connect", map[string]any{
-		"game_id":   gameID,
-		"player_id": c.UserID,
-		"chat":      true,
+	return c.GameConnectContext(context.Background(), gameID)
+}
+
+// GameConnectContext is like GameConnect but respects cancellation and
+// deadline of the given context.
+func (c *Client) GameConnectContext(ctx context.Context, gameID int64) error {
+	return c.GameConnectWith(ctx, gameID, GameConnectOpts{Chat: true})
+}
+
+// GameConnectAck is like GameConnect but waits up to timeout for the game
+// data, an error is returned when OGS reports one for the game (e.g. no such
+// game or no access) or no game data arrives in time.
+func (c *Client) GameConnectAck(gameID int64, timeout time.Duration) error {
+	watch := func(done func()) (*Subscription, error) {
+		sub, _, err := c.subscribe(fmt.Sprintf("game/%d/gamedata", gameID), done)
+		return sub, err
+	}
+	send := func(ctx context.Context) error { return c.GameConnectContext(ctx, gameID) }
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.awaitGame(ctx, gameID, "game data", watch, send)
+}
+
+// awaitGame sends a request of the game and waits for the response watched by
+// watch, an error of the game reported by OGS, or ctx to be done.
+func (c *Client) awaitGame(ctx context.Context, gameID int64, what string, watch func(done func()) (*Subscription, error), send func(context.Context) error) error {
+	done := make(chan error, 1)
+	notify := func(err error) {
+		select {
+		case done <- err:
+		default: // Only the first one matters
+		}
+	}
+	data, err := watch(func() { notify(nil) })
+	if err != nil {
+		return err
+	}
+	defer data.Cancel()
+	failure, _, err := c.subscribe(fmt.Sprintf("game/%d/error", gameID), func(msg string) {
+		notify(gameError(gameID, msg))
 	})
+	if err != nil {
+		return err
+	}
+	defer failure.Cancel()
+
+	if err := send(ctx); err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("game %d: no %s received: %w", gameID, what, ctx.Err())
+	}
+}
+
+// OnGameError starts watching errors of the game reported by OGS, e.g. after
+// GameConnect with an invalid game ID or a rejected move.
+func (c *Client) OnGameError(gameID int64, fn func(error)) error {
+	callback := func(_ any, msg string) { fn(gameError(gameID, msg)) }
+	return c.on(fmt.Sprintf("game/%d/error", gameID), callback)
+}
+
+func gameError(gameID int64, msg string) error {
+	return fmt.Errorf("game %d: %s", gameID, msg)
+}
+
+// GameConnectOpts controls what GameConnectWith receives.
+type GameConnectOpts struct {
+	Chat     bool // Receive chat messages, see OnGameChat
+	Observer bool // Connect without identifying as the logged in user
+}
+
+// GameConnectWith is like GameConnectContext with options, GameConnect
+// receives chat and identifies the logged in user if any.
+func (c *Client) GameConnectWith(ctx context.Context, gameID int64, opts GameConnectOpts) error {
+	if gameID <= 0 {
+		return fmt.Errorf("invalid game ID %d", gameID)
+	}
+	payload := map[string]any{
+		"game_id": gameID,
+		"chat":    opts.Chat,
+	}
+	if c.UserID != 0 && !opts.Observer {
+		payload["player_id"] = c.UserID
+	}
+	// Keep the gamedata sent right after connecting for OnGameData called
+	// later, moves are dispatched regardless of handlers to track the move
+	// number for GameMoveWait.
+	for _, event := range []string{"gamedata", "move"} {
+		if err := c.buffer(fmt.Sprintf("game/%d/%s", gameID, event)); err != nil {
+			return err
+		}
+	}
+	if err := c.emit(ctx, "game/connect", payload); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.games == nil {
+		c.games = make(map[int64]map[string]any)
+	}
+	c.games[gameID] = payload
+	return nil
+}
+
+// ActiveGameIDs returns IDs of games connected via GameConnect and not
+// disconnected yet in ascending order, which are restored on reconnect.
+func (c *Client) ActiveGameIDs() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	gameIDs := make([]int64, 0, len(c.games))
+	for gameID := range c.games {
+		gameIDs = append(gameIDs, gameID)
+	}
+	slices.Sort(gameIDs)
+	return gameIDs
 }
 
-// GameDisconnect disconnects a game.
+// GameDisconnect disconnects a game, event handlers are kept, call OffGame to
+// remove them.
 func (c *Client) GameDisconnect(gameID int64) error {
-	return c.socket.Emit("game/disconnect", map[string]any{
+	return c.GameDisconnectContext(context.Background(), gameID)
+}
+
+// GameDisconnectContext is like GameDisconnect but respects cancellation and
+// deadline of the given context.
+func (c *Client) GameDisconnectContext(ctx context.Context, gameID int64) error {
+	c.mu.Lock()
+	delete(c.games, gameID)
+	delete(c.buffered, fmt.Sprintf("game/%d/gamedata", gameID))
+	delete(c.buffered, fmt.Sprintf("game/%d/move", gameID))
+	delete(c.lastMoves, gameID)
+	c.mu.Unlock()
+
+	return c.emit(ctx, "game/disconnect", map[string]any{
 		"game_id": gameID,
 	})
 }
 
-// OnGameData starts watching gamedata events.
+// OnGameData starts watching gamedata events. When called after GameConnect,
+// fn is called right away with the gamedata received already if any, so the
+// initial one is never missed.
 func (c *Client) OnGameData(gameID int64, fn func(*Game)) error {
 	// The first paramter is actually of type `*socketio.Channel` (unused)
-	callback := func(_ any, g *Game) { fn(g) }
-	return c.socket.On(fmt.Sprintf("game/%d/gamedata", gameID), callback)
+	callback := func(_ any, g *Game) {
+		g.baseURL = c.baseURL
+		g.Clock.Drift = c.clockDrift()
+		fn(g)
+	}
+	event := fmt.Sprintf("game/%d/gamedata", gameID)
+	_, buffered, err := c.subscribe(event, callback)
+	if err != nil || buffered == nil {
+		return err
+	}
+	g := &Game{}
+	if err := json.Unmarshal(buffered, g); err != nil {
+		return fmt.Errorf("failed to decode buffered %s: %w", event, err)
+	}
+	callback(nil, g)
+	return nil
+}
+
+// OnGameEvent starts watching an arbitrary event of the game, e.g. "latency"
+// for game/:id/latency, for events not wrapped by the package yet.
+func (c *Client) OnGameEvent(gameID int64, event string, fn func(json.RawMessage)) error {
+	callback := func(_ any, data json.RawMessage) { fn(data) }
+	return c.on(fmt.Sprintf("game/%d/%s", gameID, event), callback)
+}
+
+// OnGamePlayerUpdate starts watching player changes of the game, e.g. a Rengo
+// team member joining or leaving, without waiting for the full gamedata.
+func (c *Client) OnGamePlayerUpdate(gameID int64, fn func(*GamePlayerUpdate)) error {
+	callback := func(_ any, u *GamePlayerUpdate) { fn(u) }
+	return c.on(fmt.Sprintf("game/%d/player_update", gameID), callback)
 }
 
 // OnGamePhase starts watching game phase changes.
 func (c *Client) OnGamePhase(gameID int64, fn func(GamePhase)) error {
 	callback := func(_ any, p GamePhase) { fn(p) }
-	return c.socket.On(fmt.Sprintf("game/%d/phase", gameID), callback)
+	return c.on(fmt.Sprintf("game/%d/phase", gameID), callback)
 }
 
 // OnGameRemovedStones starts watching game removed stones changes.
 func (c *Client) OnGameRemovedStones(gameID int64, fn func(*RemovedStones)) error {
 	callback := func(_ any, r *RemovedStones) { fn(r) }
-	return c.socket.On(fmt.Sprintf("game/%d/removed_stones", gameID), callback)
+	return c.on(fmt.Sprintf("game/%d/removed_stones", gameID), callback)
 }
 
 // OnGameRemovedStones starts watching game removed stones acceptance.
 func (c *Client) OnGameRemovedStonesAccepted(gameID int64, fn func(*RemovedStonesAccepted)) error {
 	callback := func(_ any, r *RemovedStonesAccepted) { fn(r) }
-	return c.socket.On(fmt.Sprintf("game/%d/removed_stones_accepted", gameID), callback)
+	return c.on(fmt.Sprintf("game/%d/removed_stones_accepted", gameID), callback)
 }
 
 // OnClock starts watching clock events.
 func (c *Client) OnClock(gameID int64, fn func(*Clock)) error {
-	callback := func(_ any, clock *Clock) { fn(clock) }
-	return c.socket.On(fmt.Sprintf("game/%d/clock", gameID), callback)
+	callback := func(_ any, clock *Clock) {
+		c.syncClock(clock)
+		fn(clock)
+	}
+	return c.on(fmt.Sprintf("game/%d/clock", gameID), callback)
+}
+
+// ComputedClockPair is the computed clocks of both players, see WatchClock.
+type ComputedClockPair struct {
+	Black *ComputedClock
+	White *ComputedClock
+}
+
+// WatchClock recomputes clocks of the game every interval and right after
+// every clock event, using the time control from gamedata, so a UI can simply
+// redraw on every value received. Nothing is sent before both gamedata and a
+// clock are received, call it after GameConnect. The returned func stops
+// watching and closes the channel. The interval must be positive.
+func (c *Client) WatchClock(gameID int64, interval time.Duration) (<-chan *ComputedClockPair, func(), error) {
+	if interval <= 0 {
+		return nil, nil, &ValidationError{Field: "interval", Value: interval.String()}
+	}
+	var mu sync.Mutex
+	var clock *Clock
+	var tc *TimeControl
+	kick := make(chan struct{}, 1)
+	subs, err := c.subscribeClock(gameID, func(cl *Clock, t *TimeControl) {
+		mu.Lock()
+		clock, tc = cl, t
+		mu.Unlock()
+		select {
+		case kick <- struct{}{}:
+		default: // Pending already
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan *ComputedClockPair)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			case <-kick:
+			}
+			mu.Lock()
+			cl, t := clock, tc
+			mu.Unlock()
+			if cl == nil || t == nil {
+				continue
+			}
+			pair := &ComputedClockPair{
+				Black: cl.ComputeClock(t, PlayerBlack),
+				White: cl.ComputeClock(t, PlayerWhite),
+			}
+			select {
+			case ch <- pair:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			for _, sub := range subs {
+				sub.Cancel()
+			}
+			close(stop)
+			<-done
+		})
+	}
+	return ch, cancel, nil
+}
+
+// OnComputedClock starts watching clocks of the game computed for both
+// players on every clock event, using the time control from gamedata. Clock
+// events before gamedata are skipped, call it after GameConnect.
+func (c *Client) OnComputedClock(gameID int64, fn func(black, white *ComputedClock)) error {
+	_, err := c.subscribeClock(gameID, func(clock *Clock, tc *TimeControl) {
+		if tc != nil {
+			fn(clock.ComputeClock(tc, PlayerBlack), clock.ComputeClock(tc, PlayerWhite))
+		}
+	})
+	return err
+}
+
+// subscribeClock calls update with the latest clock, from gamedata or clock
+// events, along with the time control from gamedata which is nil until
+// gamedata is received. The gamedata received already is replayed.
+func (c *Client) subscribeClock(gameID int64, update func(*Clock, *TimeControl)) ([]*Subscription, error) {
+	var mu sync.Mutex
+	var tc *TimeControl
+	onGameData := func(_ any, g *Game) {
+		if !g.TimeControl.System.Valid() {
+			c.warn("Unknown clock system", "game", gameID, "system", g.TimeControl.System)
+		}
+		g.Clock.Drift = c.clockDrift()
+		mu.Lock()
+		tc = &g.TimeControl
+		mu.Unlock()
+		update(&g.Clock, &g.TimeControl)
+	}
+	data, buffered, err := c.subscribe(fmt.Sprintf("game/%d/gamedata", gameID), onGameData)
+	if err != nil {
+		return nil, err
+	}
+	clocks, _, err := c.subscribe(fmt.Sprintf("game/%d/clock", gameID), func(_ any, clock *Clock) {
+		c.syncClock(clock)
+		mu.Lock()
+		t := tc
+		mu.Unlock()
+		update(clock, t)
+	})
+	if err != nil {
+		data.Cancel()
+		return nil, err
+	}
+	if buffered != nil {
+		g := &Game{}
+		if err := json.Unmarshal(buffered, g); err == nil {
+			onGameData(nil, g)
+		}
+	}
+	return []*Subscription{data, clocks}, nil
+}
+
+// OnGamePause starts watching game pause changes, pausedSince is zero when
+// the game is resumed. It is called with the current state on the first clock
+// event and whenever the state changes, independently of OnClock.
+func (c *Client) OnGamePause(gameID int64, fn func(paused bool, pausedSince Timestamp)) error {
+	return c.OnGamePauseState(gameID, func(state *GamePauseState) {
+		fn(state.Paused, state.PausedSince)
+	})
+}
+
+// OnGamePauseState is like OnGamePause but tells the reasons of the pause
+// when available.
+func (c *Client) OnGamePauseState(gameID int64, fn func(*GamePauseState)) error {
+	var mu sync.Mutex
+	var last *bool
+	callback := func(_ any, clock *Clock) {
+		paused := clock.IsPaused()
+		mu.Lock()
+		changed := last == nil || *last != paused
+		last = &paused
+		mu.Unlock()
+		if !changed {
+			return
+		}
+		state := &GamePauseState{}
+		if clock.Pause != nil {
+			*state = *clock.Pause
+		}
+		state.Paused = paused
+		state.PausedSince = cond(paused, clock.pausedSince(), Timestamp{})
+		fn(state)
+	}
+	return c.on(fmt.Sprintf("game/%d/clock", gameID), callback)
+}
+
+// OnGamePaused starts watching game/:id/paused events sent when the game is
+// paused, e.g. via PauseGame. Use OnGamePause to learn pauses from clock
+// events including the state at the time of connecting.
+func (c *Client) OnGamePaused(gameID int64, fn func()) error {
+	return c.on(fmt.Sprintf("game/%d/paused", gameID), fn)
+}
+
+// OnGameResumed starts watching game/:id/resumed events sent when a paused
+// game is resumed, e.g. via ResumeGame.
+func (c *Client) OnGameResumed(gameID int64, fn func()) error {
+	return c.on(fmt.Sprintf("game/%d/resumed", gameID), fn)
+}
+
+// PauseGame pauses the game, the clock stops for both players until
+// ResumeGame is called.
+func (c *Client) PauseGame(gameID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/pause", map[string]any{
+		"game_id": gameID,
+	})
+}
+
+// ResumeGame resumes a paused game.
+func (c *Client) ResumeGame(gameID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/resume", map[string]any{
+		"game_id": gameID,
+	})
 }
 
 // OnMove starts watching game move events.
 func (c *Client) OnMove(gameID int64, fn func(*GameMove)) error {
 	callback := func(_ any, m *GameMove) { fn(m) }
-	return c.socket.On(fmt.Sprintf("game/%d/move", gameID), callback)
+	return c.on(fmt.Sprintf("game/%d/move", gameID), callback)
 }
 
-// GameMove submits a move (GameConnect must be called first).
+// GameMove submits a move (GameConnect must be called first), x and y are
+// from 0 to 24 and both -1 for a pass, see PassTurn.
 func (c *Client) GameMove(gameID int64, x, y int) error {
-	return c.socket.Emit("game/move", map[string]any{
+	return c.GameMoveContext(context.Background(), gameID, x, y)
+}
+
+// GameMoveContext is like GameMove but respects cancellation and deadline of
+// the given context.
+func (c *Client) GameMoveContext(ctx context.Context, gameID int64, x, y int) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	move, err := sgfMove(x, y)
+	if err != nil {
+		return err
+	}
+	return c.emit(ctx, "game/move", map[string]any{
 		"game_id":   gameID,
 		"player_id": c.UserID,
-		"move":      fmt.Sprintf("%c%c", rune('a'+x), rune('a'+y)), // SGF
+		"move":      move,
 	})
 }
 
+// GameMoveAck is like GameMove but waits up to timeout for the move to be
+// echoed back, the rejection reported by OGS (e.g. an occupied point or a ko
+// violation) is returned as an error, see OnGameError.
+func (c *Client) GameMoveAck(gameID int64, x, y int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.GameMoveWait(ctx, gameID, x, y)
+}
+
+// GameMoveWait is like GameMoveAck but waits until ctx is done, an error
+// wrapping ctx.Err() is returned when neither the move echo nor a rejection
+// arrives, e.g. the socket is dead. The echo is the move numbered after the
+// last one received since GameConnect, so the game data must have arrived.
+func (c *Client) GameMoveWait(ctx context.Context, gameID int64, x, y int) error {
+	c.mu.Lock()
+	last, ok := c.lastMoves[gameID]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("game %d: move number unknown, no game data received", gameID)
+	}
+	watch := func(done func()) (*Subscription, error) {
+		sub, _, err := c.subscribe(fmt.Sprintf("game/%d/move", gameID), func(m *GameMove) {
+			if m.MoveNumber == last+1 {
+				done()
+			}
+		})
+		return sub, err
+	}
+	send := func(ctx context.Context) error { return c.GameMoveContext(ctx, gameID, x, y) }
+	return c.awaitGame(ctx, gameID, "move", watch, send)
+}
+
+// sgfMove encodes a move in SGF coordinates, a pass is "..".
+func sgfMove(x, y int) (string, error) {
+	if x == -1 && y == -1 {
+		return "..", nil
+	}
+	if x < 0 || x > 24 || y < 0 || y > 24 {
+		return "", fmt.Errorf("invalid move (%d, %d)", x, y)
+	}
+	return OriginCoordinate{X: x, Y: y}.ToSGF(), nil
+}
+
 func (c *Client) PassTurn(gameID int64) error {
-	return c.GameMove(gameID, -1, -1)
+	return c.PassTurnContext(context.Background(), gameID)
+}
+
+// PassTurnContext is like PassTurn but respects cancellation and deadline of
+// the given context.
+func (c *Client) PassTurnContext(ctx context.Context, gameID int64) error {
+	return c.GameMoveContext(ctx, gameID, -1, -1)
 }
 
 func (c *Client) GameResign(gameID int64) error {
-	return c.socket.Emit("game/resign", map[string]any{
+	return c.GameResignContext(context.Background(), gameID)
+}
+
+// GameResignContext is like GameResign but respects cancellation and deadline
+// of the given context.
+func (c *Client) GameResignContext(ctx context.Context, gameID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(ctx, "game/resign", map[string]any{
 		"game_id": gameID,
 	})
 }
 
+// GameCancel cancels (annuls) a game which has barely started, the game ends
+// without rating impact. OGS treats a cancel request past that point as a
+// resignation, so ErrNotCancellable is returned instead if the given state,
+// when not nil, is no longer Cancellable. The outcome is delivered to
+// OnGamePhase and OnGameData handlers.
+func (c *Client) GameCancel(gameID int64, state *GameState) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	if state != nil && !state.Cancellable() {
+		return ErrNotCancellable
+	}
+	return c.emit(context.Background(), "game/cancel", map[string]any{
+		"game_id": gameID,
+	})
+}
+
+// RequestUndo asks the opponent to undo the last move. moveNumber is the
+// current move number of the game, i.e. the number of the move to be undone,
+// which the server uses to discard stale requests. A *ValidationError is
+// returned when it's not the last move received since GameConnect.
+func (c *Client) RequestUndo(gameID int64, moveNumber int) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	if err := c.checkLastMove(gameID, moveNumber); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/undo/request", map[string]any{
+		"game_id":     gameID,
+		"move_number": moveNumber,
+	})
+}
+
+// AcceptUndo accepts the undo request of the opponent, moveNumber must be the
+// one received via OnUndoRequested. A *ValidationError is returned when it's
+// not the last move received since GameConnect.
+func (c *Client) AcceptUndo(gameID int64, moveNumber int) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	if err := c.checkLastMove(gameID, moveNumber); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/undo/accept", map[string]any{
+		"game_id":     gameID,
+		"move_number": moveNumber,
+	})
+}
+
+// checkLastMove validates moveNumber of an undo against the last move of the
+// game tracked from gamedata and move events, unchecked before gamedata of a
+// game connected via GameConnect is received.
+func (c *Client) checkLastMove(gameID int64, moveNumber int) error {
+	c.mu.Lock()
+	last, ok := c.lastMoves[gameID]
+	c.mu.Unlock()
+	if ok && moveNumber != last {
+		return &ValidationError{Field: "move_number", Value: strconv.Itoa(moveNumber), Allowed: []string{strconv.Itoa(last)}}
+	}
+	return nil
+}
+
+// CancelUndo withdraws the undo request sent via RequestUndo before the
+// opponent accepts it.
+func (c *Client) CancelUndo(gameID int64, moveNumber int) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/undo/cancel", map[string]any{
+		"game_id":     gameID,
+		"move_number": moveNumber,
+	})
+}
+
+// OnUndoRequested starts watching undo requests, moveNumber is the number of
+// the move requested to be undone. There is no way to decline, e.g. a bot
+// deciding against AcceptUndo just plays its next move, which makes the
+// request stale.
+func (c *Client) OnUndoRequested(gameID int64, fn func(moveNumber int)) error {
+	callback := func(_ any, moveNumber int) { fn(moveNumber) }
+	return c.on(fmt.Sprintf("game/%d/undo_requested", gameID), callback)
+}
+
+// OnUndoAccepted starts watching accepted undo requests, moveNumber is the
+// number of the move undone. The server resends gamedata right after, with
+// the move removed and the player in turn updated, watch it via OnGameData
+// rather than reverting any local state.
+func (c *Client) OnUndoAccepted(gameID int64, fn func(moveNumber int)) error {
+	callback := func(_ any, moveNumber int) { fn(moveNumber) }
+	return c.on(fmt.Sprintf("game/%d/undo_accepted", gameID), callback)
+}
+
+// OnUndoCanceled starts watching undo requests withdrawn by the requester,
+// moveNumber is the same as in OnUndoRequested.
+func (c *Client) OnUndoCanceled(gameID int64, fn func(moveNumber int)) error {
+	callback := func(_ any, moveNumber int) { fn(moveNumber) }
+	return c.on(fmt.Sprintf("game/%d/undo_canceled", gameID), callback)
+}
+
+// SetConditionalMoves replaces planned moves of a correspondence game,
+// moveNumber is the current move number of the game, when the tree is
+// planned. The root Move is played right away when it is my turn.
+func (c *Client) SetConditionalMoves(gameID int64, moveNumber int, tree *ConditionalMoveTree) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/conditional_moves/set", map[string]any{
+		"game_id":           gameID,
+		"player_id":         c.UserID,
+		"move_number":       moveNumber,
+		"conditional_moves": tree,
+	})
+}
+
+// OnConditionalMoves starts watching planned moves of the logged in user,
+// which are sent after GameConnect and whenever they change.
+func (c *Client) OnConditionalMoves(gameID int64, fn func(*ConditionalMoveTree)) error {
+	callback := func(_ any, res *struct {
+		Moves *ConditionalMoveTree
+	}) {
+		fn(res.Moves)
+	}
+	return c.on(fmt.Sprintf("game/%d/conditional_moves", gameID), callback)
+}
+
 func (c *Client) GameRemovedStonesAccept(gameID int64, g *GameState) error {
-	return c.socket.Emit("game/removed_stones/accept", map[string]any{
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/removed_stones/accept", map[string]any{
 		"game_id": gameID,
 		"stones":  g.RemovalString(),
 	})
 }
 
+// GameRemovedStonesReject rejects the current removal proposal, e.g. when
+// the opponent marked a living group as dead, OGS resumes the game for the
+// players to settle it by playing.
+func (c *Client) GameRemovedStonesReject(gameID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "game/removed_stones/reject", map[string]any{
+		"game_id": gameID,
+	})
+}
+
+// GameRemovedStonesSet marks (removed is true) or unmarks stones as dead
+// during stone removal phase, usually a whole group at once. The result is
+// sent to OnGameRemovedStones handlers of both players, who need to accept
+// again via GameRemovedStonesAccept.
+func (c *Client) GameRemovedStonesSet(gameID int64, removed bool, coords []OriginCoordinate) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	if len(coords) == 0 {
+		return fmt.Errorf("no stones to set")
+	}
+	return c.emit(context.Background(), "game/removed_stones/set", map[string]any{
+		"game_id": gameID,
+		"removed": removed,
+		"stones":  SGFVertices(coords),
+	})
+}
+
 func (c *Client) GameListQuery(list GameListType, from, limit int, where *GameListWhere, timeout time.Duration) (*GameListResponse, error) {
 	data := map[string]any{
 		"list":    list,
@@ -131,20 +1233,151 @@ func (c *Client) GameListQuery(list GameListType, from, limit int, where *GameLi
 		"limit":   limit,
 		"where":   where,
 	}
-	res, err := c.socket.Ack("gamelist/query", data, timeout)
+	res, err := c.Ack("gamelist/query", data, timeout)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := GameListResponse{}
-	if err := json.Unmarshal([]byte(res), &resp); err != nil {
+	if err := json.Unmarshal(res, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// pingTimeout is how long Ping waits for the pong.
+const pingTimeout = 10 * time.Second
+
+// Ping measures the round trip latency to OGS, and the drift of the local
+// clock which is applied to clocks received afterwards, see Clock.Drift. Like
+// the OGS web client, the server time in the pong is assumed to be taken
+// half way through the round trip.
+func (c *Client) Ping() (time.Duration, error) {
+	return c.ping(pingTimeout)
+}
+
+// StartKeepalive pings OGS every interval in the background. A connection
+// not answering a ping within interval is considered dropped silently, e.g.
+// after long inactivity, and is reconnected according to the reconnect
+// policy. Calling it again restarts with the new interval, Disconnect stops
+// it. The interval must be positive.
+func (c *Client) StartKeepalive(interval time.Duration) error {
+	if interval <= 0 {
+		return &ValidationError{Field: "interval", Value: interval.String()}
+	}
+	stop := make(chan struct{})
+	c.mu.Lock()
+	if c.keepalive != nil {
+		close(c.keepalive)
+	}
+	c.keepalive = stop
+	c.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			c.mu.Lock()
+			conn, reconnecting := c.socket, c.reconnecting
+			c.mu.Unlock()
+			if conn == nil || reconnecting {
+				continue
+			}
+			if _, err := c.ping(interval); err != nil {
+				select {
+				case <-stop:
+					return // Disconnected while waiting
+				default:
+				}
+				c.debug("Keepalive ping failed, reconnecting", "error", err)
+				conn.Close()
+				go c.reconnect(conn)
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *Client) ping(timeout time.Duration) (time.Duration, error) {
+	sent := time.Now()
+	pongs := make(chan netPong, 1)
+	sub, _, err := c.subscribe("net/pong", func(p netPong) {
+		if p.Client.UnixMilli() == sent.UnixMilli() {
+			select {
+			case pongs <- p:
+			default:
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer sub.Cancel()
+
+	c.mu.Lock()
+	drift, latency := c.drift, c.latency
+	c.mu.Unlock()
+	if err := c.emit(context.Background(), "net/ping", map[string]any{
+		"client":  sent.UnixMilli(),
+		"drift":   drift.Milliseconds(),
+		"latency": latency.Milliseconds(),
+	}); err != nil {
+		return 0, err
+	}
+
+	select {
+	case p := <-pongs:
+		now := time.Now()
+		latency := now.Sub(sent)
+		c.mu.Lock()
+		c.latency = latency
+		c.drift = now.Add(-latency / 2).Sub(p.Server.Time)
+		c.mu.Unlock()
+		return latency, nil
+	case <-time.After(timeout):
+		return 0, fmt.Errorf("no pong received in %v", timeout)
+	}
+}
+
+// Latency returns the round trip latency to OGS measured by the latest Ping,
+// zero if never measured.
+func (c *Client) Latency() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latency
+}
+
+// syncClock updates the drift from the server time of a clock event, which is
+// taken half of the latency measured by Ping ago, then sets Clock.Drift.
+func (c *Client) syncClock(clock *Clock) {
+	c.mu.Lock()
+	if !clock.Now.IsZero() {
+		c.drift = time.Now().Add(-c.latency / 2).Sub(clock.Now.Time)
+	}
+	clock.Drift = c.drift
+	c.mu.Unlock()
+}
+
+// clockDrift returns the drift measured by Ping or clock events, see
+// Clock.Drift.
+func (c *Client) clockDrift() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drift
+}
+
+type netPong struct {
+	Client Timestamp
+	Server Timestamp
+}
+
 func (c *Client) NetPing(drift, latency int64) error {
-	return c.socket.Emit("net/ping", map[string]any{
+	return c.emit(context.Background(), "net/ping", map[string]any{
 		"client":  time.Now().UnixMilli(),
 		"drift":   drift,
 		"latency": latency,
@@ -152,41 +1385,170 @@ func (c *Client) NetPing(drift, latency int64) error {
 }
 
 func (c *Client) OnNetPong(fn func(drift, latency int64)) error {
-	type pong struct {
-		Client Timestamp
-		Server Timestamp
-	}
-	callback := func(_ any, p *pong) {
+	callback := func(_ any, p *netPong) {
 		now := time.Now()
 		latency := now.UnixMilli() - p.Client.UnixMilli()
 		drift := now.UnixMilli() - latency/2 - p.Server.UnixMilli()
 		fn(drift, latency)
 	}
-	return c.socket.On("net/pong", callback)
+	return c.on("net/pong", callback)
 }
 
 func (c *Client) OnActiveGame(fn func(*GameListEntry)) error {
 	callback := func(_ any, g *GameListEntry) { fn(g) }
-	return c.socket.On("active_game", callback)
+	return c.on("active_game", callback)
 }
 
 func (c *Client) ChatJoin(gameID int64) error {
-	return c.socket.Emit("chat/join", map[string]any{
+	return c.emit(context.Background(), "chat/join", map[string]any{
 		"channel": fmt.Sprintf("game-%d", gameID),
 	})
 }
 
 // GameChat sends a messaage to the game, this is not hidden or personal.
+//
+// Deprecated: Use SendGameChat instead.
 func (c *Client) GameChat(gameID int64, moveNumber int, message string) error {
-	return c.socket.Emit("game/chat", map[string]any{
+	return c.SendGameChat(gameID, message, moveNumber)
+}
+
+// SendGameChat sends a message to the main chat channel of the game, visible
+// to both players and spectators. moveNumber is the move the message refers
+// to, usually the current one.
+func (c *Client) SendGameChat(gameID int64, body string, moveNumber int) error {
+	return c.SendGameChatTo(gameID, ChatMain, body, moveNumber)
+}
+
+// SendGameChatTo is like SendGameChat but sends to the given channel, e.g.
+// ChatMalkovich for comments revealed after the game. Malkovich chat is hidden
+// from the opponent only until the game ends, and a typo would send private
+// comments elsewhere, so a *ValidationError is returned for an unknown
+// channel.
+func (c *Client) SendGameChatTo(gameID int64, channel ChatChannel, body string, moveNumber int) error {
+	if !slices.Contains(chatChannels, channel) {
+		var allowed []string
+		for _, ch := range chatChannels {
+			allowed = append(allowed, string(ch))
+		}
+		return &ValidationError{Field: "channel", Value: string(channel), Allowed: allowed}
+	}
+	return c.emit(context.Background(), "game/chat", map[string]any{
 		"game_id":     gameID,
-		"type":        "main",
+		"body":        body,
+		"type":        channel,
 		"move_number": moveNumber,
-		"body":        message,
 	})
 }
 
+var chatChannels = []ChatChannel{ChatMain, ChatMalkovich, ChatSpectator}
+
+// OnGameChat starts watching chat messages of the game, including the chat
+// history sent by the server right after GameConnect.
 func (c *Client) OnGameChat(gameID int64, fn func(*GameChat)) error {
 	callback := func(_ any, chat *GameChat) { fn(chat) }
-	return c.socket.On(fmt.Sprintf("game/%d/chat", gameID), callback)
+	return c.on(fmt.Sprintf("game/%d/chat", gameID), callback)
+}
+
+// OnGameChatLine is like OnGameChat but only passes the chat line, whose
+// Channel is filled from the message when missing. Chat is only received
+// after GameConnect, or GameConnectWith and GameConnectOpts.Chat set.
+func (c *Client) OnGameChatLine(gameID int64, fn func(*GameChatLine)) error {
+	return c.OnGameChat(gameID, func(chat *GameChat) {
+		line := chat.Line
+		if line.Channel == "" {
+			line.Channel = chat.Channel
+		}
+		fn(&line)
+	})
+}
+
+// SeekGraphConnect starts receiving open challenges, see OnSeekGraph. The
+// subscription is restored after a reconnect.
+func (c *Client) SeekGraphConnect() error {
+	if err := c.emit(context.Background(), "seek_graph/connect", seekGraphPayload); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seekGraph = true
+	return nil
+}
+
+// SeekGraphDisconnect stops receiving open challenges.
+func (c *Client) SeekGraphDisconnect() error {
+	c.mu.Lock()
+	c.seekGraph = false
+	c.mu.Unlock()
+	return c.emit(context.Background(), "seek_graph/disconnect", seekGraphPayload)
+}
+
+var seekGraphPayload = map[string]any{"channel": "global"}
+
+// OnSeekGraph starts watching open challenges after SeekGraphConnect. All
+// open challenges are sent initially, then new and removed ones as they
+// change, see SeekGraphEntry.Removed. Entries failing to decode are logged
+// and skipped.
+func (c *Client) OnSeekGraph(fn func([]SeekGraphEntry)) error {
+	callback := func(_ any, data []json.RawMessage) {
+		entries := make([]SeekGraphEntry, 0, len(data))
+		for _, d := range data {
+			var e SeekGraphEntry
+			if err := json.Unmarshal(d, &e); err != nil {
+				c.warn("Skipped invalid seek graph entry", "error", err)
+				continue
+			}
+			entries = append(entries, e)
+		}
+		fn(entries)
+	}
+	return c.on("seekgraph/global", callback)
+}
+
+// OnNotification starts watching notifications of the logged in user, which
+// are sent after authentication.
+func (c *Client) OnNotification(fn func(*Notification)) error {
+	callback := func(_ any, n *Notification) { fn(n) }
+	return c.on("notification", callback)
+}
+
+// OnChallenge starts watching challenges received by the logged in user, e.g.
+// for a bot to decide via AcceptChallenge or RejectChallenge without polling
+// IncomingChallenges.
+func (c *Client) OnChallenge(fn func(*Challenge)) error {
+	callback := func(_ any, ch *restChallengeEntry) {
+		challenge := ch.challenge()
+		fn(&challenge)
+	}
+	return c.on("challenge", callback)
+}
+
+// OnChallengeWithdrawn starts watching challenges withdrawn by the challenger
+// before being accepted or rejected.
+func (c *Client) OnChallengeWithdrawn(fn func(challengeID int64)) error {
+	// Either the ID or an object carrying it
+	callback := func(_ any, data json.RawMessage) {
+		var id int64
+		if json.Unmarshal(data, &id) != nil {
+			var removed struct {
+				ChallengeID int64 `json:"challenge_id"`
+			}
+			if err := json.Unmarshal(data, &removed); err != nil {
+				c.warn("Failed to decode event", "event", "challenge/removed", "error", err)
+				return
+			}
+			id = removed.ChallengeID
+		}
+		fn(id)
+	}
+	return c.on("challenge/removed", callback)
+}
+
+// DeleteNotification clears a notification, e.g. after it is handled.
+func (c *Client) DeleteNotification(id string) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.emit(context.Background(), "notification/delete", map[string]any{
+		"notification_id": id,
+	})
 }
@@ -0,0 +1,1303 @@
+package googs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type emitted struct {
+	event string
+	args  any
+}
+
+// fakeSocket records emitted events, optionally blocking Emit until unblock
+// is closed.
+type fakeSocket struct {
+	mu       sync.Mutex
+	emits    []emitted
+	handlers map[string]any
+	acks     map[string]string // Event => response of Ack
+	unblock  chan struct{}
+	closed   bool
+}
+
+func (s *fakeSocket) Emit(event string, args any) error {
+	if s.unblock != nil {
+		<-s.unblock
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emits = append(s.emits, emitted{event, args})
+	return nil
+}
+
+func (s *fakeSocket) On(event string, fn any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlers == nil {
+		s.handlers = make(map[string]any)
+	}
+	s.handlers[event] = fn
+	return nil
+}
+
+func (s *fakeSocket) Ack(event string, args any, timeout time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emits = append(s.emits, emitted{event, args})
+	res, ok := s.acks[event]
+	if !ok {
+		return "", errors.New("fakeSocket: Ack timeout")
+	}
+	return res, nil
+}
+
+func (s *fakeSocket) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+// receive delivers an event to the registered handler the same way as
+// socketio does, payload is decoded into the type of the second parameter.
+func (s *fakeSocket) receive(event, payload string) {
+	s.mu.Lock()
+	fn, ok := s.handlers[event]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	f := reflect.ValueOf(fn)
+	args := []reflect.Value{reflect.Zero(f.Type().In(0))}
+	if f.Type().NumIn() == 2 {
+		arg := reflect.New(f.Type().In(1))
+		if err := json.Unmarshal([]byte(payload), arg.Interface()); err != nil {
+			return
+		}
+		args = append(args, arg.Elem())
+	}
+	f.Call(args)
+}
+
+func (s *fakeSocket) events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []string
+	for _, e := range s.emits {
+		events = append(events, e.event)
+	}
+	return events
+}
+
+func (s *fakeSocket) lastEmit() emitted {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.emits) == 0 {
+		return emitted{}
+	}
+	return s.emits[len(s.emits)-1]
+}
+
+func TestClient_GameConnectContext(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{UserID: 42, socket: sock}
+
+	if err := c.GameConnectContext(context.Background(), 123); err != nil {
+		t.Fatalf("GameConnectContext() want no error, got %v", err)
+	}
+	got := sock.lastEmit()
+	if got.event != "game/connect" {
+		t.Errorf("GameConnectContext() want event %q, got %q", "game/connect", got.event)
+	}
+	if args := got.args.(map[string]any); args["game_id"] != int64(123) || args["player_id"] != int64(42) {
+		t.Errorf("GameConnectContext() got unexpected payload %v", args)
+	}
+	if err := c.GameConnectContext(context.Background(), 45); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ActiveGameIDs(); !slices.Equal(got, []int64{45, 123}) {
+		t.Errorf("ActiveGameIDs() want [45 123], got %v", got)
+	}
+}
+
+func TestClient_GameConnectWith(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{UserID: 42, socket: sock}
+
+	if err := c.GameConnectWith(context.Background(), 123, GameConnectOpts{Observer: true}); err != nil {
+		t.Fatalf("GameConnectWith() want no error, got %v", err)
+	}
+	args := sock.lastEmit().args.(map[string]any)
+	if _, ok := args["player_id"]; ok || args["chat"] != false {
+		t.Errorf("GameConnectWith() as observer without chat got unexpected payload %v", args)
+	}
+
+	n := len(sock.events())
+	for _, gameID := range []int64{0, -1} {
+		if err := c.GameConnectWith(context.Background(), gameID, GameConnectOpts{}); err == nil {
+			t.Errorf("GameConnectWith(%d) want error, got nil", gameID)
+		}
+	}
+	if len(sock.events()) != n {
+		t.Errorf("GameConnectWith() with invalid game want nothing emitted, got %v", sock.events()[n:])
+	}
+}
+
+func TestClient_GameConnectContext_Cancel(t *testing.T) {
+	sock := &fakeSocket{unblock: make(chan struct{})}
+	defer close(sock.unblock)
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.GameConnectContext(ctx, 123); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GameConnectContext() want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := c.GameMoveContext(ctx, 123, 3, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("GameMoveContext() want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestClient_Emit_NotConnected(t *testing.T) {
+	c := &Client{Token: Token{AccessToken: "token"}}
+
+	if err := c.GameMove(123, 3, 3); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("GameMove() want %v, got %v", ErrNotConnected, err)
+	}
+	if err := c.Emit("net/ping", nil); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Emit() want %v, got %v", ErrNotConnected, err)
+	}
+	if _, err := c.Ack("net/ping", nil, time.Second); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Ack() want %v, got %v", ErrNotConnected, err)
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	sockets := make(chan *fakeSocket, 10)
+	c := &Client{Auth: Auth{UserJWT: "jwt"}, dial: func() (socket, error) {
+		s := &fakeSocket{}
+		sockets <- s
+		return s, nil
+	}}
+	var attempts atomic.Int32
+	c.SetReconnectPolicy(3, time.Millisecond, 5*time.Millisecond)
+	c.OnReconnect(func(attempt int) { attempts.Store(int32(attempt)) })
+
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+	first := <-sockets
+
+	moves := make(chan *GameMove, 1)
+	c.OnMove(123, func(m *GameMove) { moves <- m })
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+
+	first.receive("disconnection", "")
+	var second *fakeSocket
+	select {
+	case second = <-sockets:
+	case <-time.After(time.Second):
+		t.Fatal("reconnect did not happen")
+	}
+
+	// connect() restores handlers and games right after dial, wait for it.
+	deadline := time.Now().Add(time.Second)
+	for len(second.events()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got, want := second.events(), []string{"authenticate", "game/connect"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events after reconnect want %v, got %v", want, got)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("OnReconnect want attempt 1, got %d", attempts.Load())
+	}
+
+	second.receive("game/123/move", `{"game_id": 123, "move": [3, 3, 100], "move_number": 1}`)
+	select {
+	case m := <-moves:
+		if m.Move.X != 3 || m.MoveNumber != 1 {
+			t.Errorf("OnMove after reconnect got unexpected move %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Error("OnMove handler did not survive reconnect")
+	}
+}
+
+func TestClient_StartKeepalive(t *testing.T) {
+	sockets := make(chan *fakeSocket, 10)
+	c := &Client{dial: func() (socket, error) {
+		s := &fakeSocket{}
+		sockets <- s
+		return s, nil
+	}}
+	c.SetReconnectPolicy(1, time.Millisecond, time.Millisecond)
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+	first := <-sockets
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		var verr *ValidationError
+		if err := c.StartKeepalive(interval); !errors.As(err, &verr) {
+			t.Errorf("StartKeepalive(%v) want ValidationError, got %v", interval, err)
+		}
+	}
+
+	// The first socket never answers pings
+	if err := c.StartKeepalive(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	var second *fakeSocket
+	select {
+	case second = <-sockets:
+	case <-time.After(time.Second):
+		t.Fatal("want reconnect after unanswered ping, got none")
+	}
+	first.mu.Lock()
+	closed := first.closed
+	first.mu.Unlock()
+	if !closed || !slices.Contains(first.events(), "net/ping") {
+		t.Errorf("want the silent socket pinged and closed, got events %v, closed %v", first.events(), closed)
+	}
+
+	c.Disconnect()
+	time.Sleep(50 * time.Millisecond)
+	n := len(second.events())
+	time.Sleep(50 * time.Millisecond)
+	if len(second.events()) != n {
+		t.Errorf("want no ping after Disconnect, got %v", second.events())
+	}
+}
+
+func TestClient_Reconnect_DisconnectWhileDialing(t *testing.T) {
+	var c *Client
+	dials := 0
+	dialed := make(chan *fakeSocket, 10)
+	c = &Client{dial: func() (socket, error) {
+		s := &fakeSocket{}
+		if dials++; dials > 1 {
+			c.Disconnect() // While reconnecting
+		}
+		dialed <- s
+		return s, nil
+	}}
+	c.SetReconnectPolicy(3, time.Millisecond, time.Millisecond)
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+	first := <-dialed
+
+	c.reconnect(first)
+	second := <-dialed
+	if c.sock() != first || !second.closed {
+		t.Errorf("socket dialed during Disconnect() want closed and unused, got closed %v, used %v", second.closed, c.sock() == second)
+	}
+	if len(dialed) != 0 {
+		t.Errorf("want no more reconnects after Disconnect(), got %d", len(dialed))
+	}
+}
+
+func TestClient_Reconnect_Disabled(t *testing.T) {
+	dials := 0
+	c := &Client{dial: func() (socket, error) {
+		dials++
+		return &fakeSocket{}, nil
+	}}
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.reconnect(c.sock()) // Default policy does not reconnect
+	c.SetReconnectPolicy(3, time.Millisecond, time.Millisecond)
+	c.Disconnect()
+	c.reconnect(c.sock()) // Disconnect() was called
+	if dials != 1 {
+		t.Errorf("want no reconnect, got %d dials", dials)
+	}
+}
+
+func TestClient_GameChat(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	chats := make(chan *GameChat, 1)
+	if err := c.OnGameChat(123, func(chat *GameChat) { chats <- chat }); err != nil {
+		t.Fatal(err)
+	}
+	lines := make(chan *GameChatLine, 1)
+	if err := c.OnGameChatLine(123, func(line *GameChatLine) { lines <- line }); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/chat", `{"channel": "main", "line": {"chat_id": "abc", "body": "hi", "move_number": 5, "player_id": 7, "username": "alice"}}`)
+	select {
+	case got := <-chats:
+		if got.Channel != "main" || got.Line.Body != "hi" || got.Line.MoveNumber != 5 || got.Line.Username != "alice" {
+			t.Errorf("OnGameChat() got unexpected chat %+v", got)
+		}
+	default:
+		t.Error("OnGameChat() handler was not called")
+	}
+	select {
+	case got := <-lines:
+		if got.Channel != ChatMain || got.Body != "hi" {
+			t.Errorf("OnGameChatLine() got unexpected line %+v", got)
+		}
+	default:
+		t.Error("OnGameChatLine() handler was not called")
+	}
+
+	if err := c.SendGameChat(123, "hello", 6); err != nil {
+		t.Fatalf("SendGameChat() want no error, got %v", err)
+	}
+	got := sock.lastEmit()
+	want := map[string]any{"game_id": int64(123), "body": "hello", "type": ChatMain, "move_number": 6}
+	if got.event != "game/chat" || !reflect.DeepEqual(got.args, want) {
+		t.Errorf("SendGameChat() want %v, got %q %v", want, got.event, got.args)
+	}
+
+	if err := c.SendGameChatTo(123, ChatMalkovich, "囲碁 gg 👍", 6); err != nil {
+		t.Fatalf("SendGameChatTo() want no error, got %v", err)
+	}
+	if args := sock.lastEmit().args.(map[string]any); args["type"] != ChatMalkovich || args["body"] != "囲碁 gg 👍" {
+		t.Errorf("SendGameChatTo() got unexpected payload %v", args)
+	}
+
+	n := len(sock.events())
+	var verr *ValidationError
+	if err := c.SendGameChatTo(123, "malkovic", "secret", 6); !errors.As(err, &verr) || verr.Field != "channel" {
+		t.Errorf("SendGameChatTo() want *ValidationError for unknown channel, got %v", err)
+	}
+	if len(sock.events()) != n {
+		t.Error("SendGameChatTo() want nothing emitted for unknown channel")
+	}
+}
+
+func TestGameChat_Unmarshal(t *testing.T) {
+	// Captured from OGS, which escapes non-ASCII characters
+	const payload = `{"channel":"spectator","line":{"chat_id":"c1d2","body":"\u56f2\u7881 gg \ud83d\udc4d","date":1700000000,"move_number":212,"channel":"spectator","player_id":7,"username":"alice","professional":0,"ranking":25.3}}`
+	var got GameChat
+	if err := json.Unmarshal([]byte(payload), &got); err != nil {
+		t.Fatalf("Unmarshal() want no error, got %v", err)
+	}
+	if got.Channel != ChatSpectator || got.Line.Channel != ChatSpectator || got.Line.Body != "囲碁 gg 👍" || got.Line.Date.Unix() != 1700000000 {
+		t.Errorf("Unmarshal() got unexpected chat %+v", got)
+	}
+}
+
+func TestClient_Anonymous(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{dial: func() (socket, error) { return sock, nil }}
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GameConnect(123); err != nil {
+		t.Fatalf("GameConnect() want no error, got %v", err)
+	}
+	if got, want := sock.events(), []string{"game/connect"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("anonymous client want events %v, got %v", want, got)
+	}
+	if _, ok := sock.lastEmit().args.(map[string]any)["player_id"]; ok {
+		t.Errorf("GameConnect() as observer want no player_id, got %v", sock.lastEmit().args)
+	}
+
+	if err := c.GameMove(123, 3, 3); !errors.Is(err, ErrAuthRequired) {
+		t.Errorf("GameMove() want %v, got %v", ErrAuthRequired, err)
+	}
+	if _, err := c.Overview(); !errors.Is(err, ErrAuthRequired) {
+		t.Errorf("Overview() want %v, got %v", ErrAuthRequired, err)
+	}
+}
+
+func TestClient_Undo(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	var requested, accepted, canceled int
+	var moves int
+	c.OnUndoRequested(123, func(n int) { requested = n })
+	c.OnUndoAccepted(123, func(n int) { accepted = n })
+	c.OnUndoCanceled(123, func(n int) { canceled = n })
+	c.OnGameData(123, func(g *Game) { moves = len(g.Moves) })
+	sock.receive("game/123/undo_requested", "15")
+	sock.receive("game/123/undo_canceled", "15")
+	sock.receive("game/123/undo_requested", "15")
+	sock.receive("game/123/undo_accepted", "15")
+	sock.receive("game/123/gamedata", `{"game_id": 123, "moves": [[3, 3, 1000], [15, 15, 1000]]}`)
+	if requested != 15 || canceled != 15 || accepted != 15 {
+		t.Errorf("want undo of move 15 requested, canceled and accepted, got %d, %d and %d", requested, canceled, accepted)
+	}
+	if moves != 2 {
+		t.Errorf("want gamedata resent after undo with 2 moves, got %d", moves)
+	}
+
+	for _, tc := range []struct {
+		event string
+		send  func(int64, int) error
+	}{
+		{"game/undo/request", c.RequestUndo},
+		{"game/undo/accept", c.AcceptUndo},
+		{"game/undo/cancel", c.CancelUndo},
+	} {
+		if err := tc.send(123, 15); err != nil {
+			t.Fatalf("%s want no error, got %v", tc.event, err)
+		}
+		got := sock.lastEmit()
+		want := map[string]any{"game_id": int64(123), "move_number": 15}
+		if got.event != tc.event || !reflect.DeepEqual(got.args, want) {
+			t.Errorf("want %s %v, got %s %v", tc.event, want, got.event, got.args)
+		}
+	}
+}
+
+func TestClient_Undo_LastMove(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "moves": [[3, 3, 1000], [15, 15, 1000]]}`)
+	for _, tc := range []struct {
+		event      string
+		send       func(int64, int) error
+		moveNumber int
+		wantErr    bool
+	}{
+		{"game/undo/request", c.RequestUndo, 1, true},
+		{"game/undo/request", c.RequestUndo, 3, true},
+		{"game/undo/request", c.RequestUndo, 2, false},
+		{"game/undo/accept", c.AcceptUndo, 2, false},
+	} {
+		n := len(sock.events())
+		err := tc.send(123, tc.moveNumber)
+		var verr *ValidationError
+		if tc.wantErr {
+			if !errors.As(err, &verr) || len(sock.events()) != n {
+				t.Errorf("%s of move %d want *ValidationError and nothing emitted, got %v, %v", tc.event, tc.moveNumber, err, sock.events()[n:])
+			}
+			continue
+		}
+		got := sock.lastEmit()
+		want := map[string]any{"game_id": int64(123), "move_number": tc.moveNumber}
+		if err != nil || got.event != tc.event || !reflect.DeepEqual(got.args, want) {
+			t.Errorf("want %s %v, got %s %v, %v", tc.event, want, got.event, got.args, err)
+		}
+	}
+
+	sock.receive("game/123/move", `{"game_id": 123, "move_number": 3, "move": [4, 4, 1000]}`)
+	if err := c.AcceptUndo(123, 2); err == nil {
+		t.Errorf("AcceptUndo() of move 2 after move 3 want error, got nil")
+	}
+	if err := c.AcceptUndo(123, 3); err != nil {
+		t.Errorf("AcceptUndo() of move 3 want no error, got %v", err)
+	}
+}
+
+func TestClient_GameCancel(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	var phase GamePhase
+	c.OnGamePhase(123, func(p GamePhase) { phase = p })
+	if err := c.GameCancel(123, &GameState{Phase: PlayPhase, MoveNumber: 1}); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	got := sock.lastEmit()
+	want := map[string]any{"game_id": int64(123)}
+	if got.event != "game/cancel" || !reflect.DeepEqual(got.args, want) {
+		t.Errorf("want game/cancel %v, got %s %v", want, got.event, got.args)
+	}
+	sock.receive("game/123/phase", `"finished"`)
+	if phase != FinishedPhase {
+		t.Errorf("want phase %q after cancel, got %q", FinishedPhase, phase)
+	}
+
+	n := len(sock.events())
+	if err := c.GameCancel(123, &GameState{Phase: PlayPhase, MoveNumber: 2}); !errors.Is(err, ErrNotCancellable) {
+		t.Errorf("want ErrNotCancellable, got %v", err)
+	}
+	if len(sock.events()) != n {
+		t.Errorf("want nothing emitted for a game not cancellable, got %v", sock.events()[n:])
+	}
+}
+
+func TestClient_GameRemovedStonesSet(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	var got *RemovedStones
+	c.OnGameRemovedStones(123, func(r *RemovedStones) { got = r })
+	group := []OriginCoordinate{{X: 4, Y: 3}, {X: 7, Y: 3}, {X: 8, Y: 3}}
+	if err := c.GameRemovedStonesSet(123, true, group); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	emit := sock.lastEmit()
+	want := map[string]any{"game_id": int64(123), "removed": true, "stones": "edhdid"}
+	if emit.event != "game/removed_stones/set" || !reflect.DeepEqual(emit.args, want) {
+		t.Errorf("want game/removed_stones/set %v, got %s %v", want, emit.event, emit.args)
+	}
+	sock.receive("game/123/removed_stones", `{"removed": true, "stones": "edhdid", "all_removed": "aaedhdid"}`)
+	if got == nil || !got.Removed || got.AllRemoved != "aaedhdid" {
+		t.Errorf("want removed stones reflected, got %+v", got)
+	}
+
+	if err := c.GameRemovedStonesSet(123, false, nil); err == nil {
+		t.Error("want error for no stones, got nil")
+	}
+}
+
+func TestClient_GameRemovedStonesReject(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	game := &Game{Phase: StoneRemovalPhase, Players: Players{Black: Player{ID: 1}, White: Player{ID: 2}}}
+	c.OnGameRemovedStones(123, func(r *RemovedStones) { game.UpdateRemovedStones(r) })
+	c.OnGameRemovedStonesAccepted(123, func(r *RemovedStonesAccepted) { game.UpdateRemovedStonesAccepted(r) })
+
+	sock.receive("game/123/removed_stones", `{"removed": true, "stones": "dd", "all_removed": "dd"}`)
+	sock.receive("game/123/removed_stones_accepted", `{"player_id": 2, "stones": "dd", "phase": "stone removal"}`)
+	if game.RemovalAccepted() || game.Players.White.AcceptedStones == nil {
+		t.Fatalf("want only white accepted, got %+v", game.Players)
+	}
+
+	if err := c.GameRemovedStonesReject(123); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	emit := sock.lastEmit()
+	want := map[string]any{"game_id": int64(123)}
+	if emit.event != "game/removed_stones/reject" || !reflect.DeepEqual(emit.args, want) {
+		t.Errorf("want game/removed_stones/reject %v, got %s %v", want, emit.event, emit.args)
+	}
+
+	sock.receive("game/123/removed_stones", `{"removed": false, "stones": "dd", "all_removed": ""}`)
+	if game.Players.Black.AcceptedStones != nil || game.Players.White.AcceptedStones != nil {
+		t.Errorf("want acceptances reset after removal change, got %+v", game.Players)
+	}
+	sock.receive("game/123/removed_stones_accepted", `{"player_id": 1, "stones": "", "phase": "stone removal"}`)
+	sock.receive("game/123/removed_stones_accepted", `{"player_id": 2, "stones": "", "phase": "finished", "outcome": "3.5 points", "winner": 2}`)
+	if !game.RemovalAccepted() || game.Phase != FinishedPhase || game.WinnerID != 2 {
+		t.Errorf("want removal accepted by both and game finished, got %+v", game)
+	}
+}
+
+func TestClient_OnGamePause(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+
+	clocks := 0
+	var pauses []bool
+	var since Timestamp
+	c.OnClock(123, func(*Clock) { clocks++ })
+	c.OnGamePause(123, func(paused bool, pausedSince Timestamp) {
+		pauses = append(pauses, paused)
+		since = pausedSince
+	})
+	sock.receive("game/123/clock", `{"game_id": 123}`)
+	sock.receive("game/123/clock", `{"game_id": 123, "paused_since": 1672531200000}`)
+	sock.receive("game/123/clock", `{"game_id": 123, "paused_since": 1672531200000}`)
+	if clocks != 3 {
+		t.Errorf("OnClock want 3 events, got %d", clocks)
+	}
+	if !reflect.DeepEqual(pauses, []bool{false, true}) || !since.Equal(time.UnixMilli(1672531200000)) {
+		t.Errorf("OnGamePause want [false true] since 1672531200000, got %v since %v", pauses, since)
+	}
+
+	var state *GamePauseState
+	c.OnGamePauseState(456, func(s *GamePauseState) { state = s })
+	// Captured from OGS, paused manually by player 42
+	sock.receive("game/456/clock", `{"game_id": 456, "current_player": 42, "black_player_id": 42, "white_player_id": 43,
+		"pause": {"paused": true, "paused_since": 1672531200000, "pause_control": {"paused": {"pausing_player_id": 42, "pauses_left": 4}}}}`)
+	if state == nil || !state.Paused || state.OnVacation(43) || !state.PausedSince.Equal(time.UnixMilli(1672531200000)) {
+		t.Fatalf("OnGamePauseState() got unexpected state %+v", state)
+	}
+	if id, err := state.PausingPlayerID(); id != 42 || err != nil {
+		t.Errorf("PausingPlayerID() want 42, got %d, %v", id, err)
+	}
+	sock.receive("game/456/clock", `{"game_id": 456, "pause": {"paused": true, "pause_control": {"vacation-43": true}}}`)
+	if id, _ := state.PausingPlayerID(); id != 42 {
+		t.Errorf("OnGamePauseState() want no call while still paused, got %+v", state)
+	}
+	sock.receive("game/456/clock", `{"game_id": 456, "pause": {"paused": false, "pause_control": {}}}`)
+	if id, err := state.PausingPlayerID(); state.Paused || id != 0 || err != nil {
+		t.Errorf("OnGamePauseState() want resumed, got %+v, %v", state, err)
+	}
+	sock.receive("game/456/clock", `{"game_id": 456, "pause": {"paused": true, "pause_control": {"paused": true}}}`)
+	if _, err := state.PausingPlayerID(); err == nil {
+		t.Errorf("PausingPlayerID() of malformed pause want error, got nil")
+	}
+
+	var paused, resumed int
+	c.OnGamePaused(789, func() { paused++ })
+	c.OnGameResumed(789, func() { resumed++ })
+	sock.receive("game/789/clock", `{"game_id": 789, "paused_since": 1672531200000}`)
+	sock.receive("game/789/clock", `{"game_id": 789}`)
+	if paused != 0 || resumed != 0 {
+		t.Errorf("OnGamePaused() and OnGameResumed() want no call on clock events, got %d and %d", paused, resumed)
+	}
+	sock.receive("game/789/paused", `{"game_id": 789}`)
+	sock.receive("game/789/resumed", `{"game_id": 789}`)
+	if paused != 1 || resumed != 1 {
+		t.Errorf("OnGamePaused() and OnGameResumed() want called once, got %d and %d", paused, resumed)
+	}
+
+	if err := c.PauseGame(123); err != nil || sock.lastEmit().event != "game/pause" {
+		t.Errorf("PauseGame() want game/pause emitted, got %v, %v", sock.lastEmit(), err)
+	}
+	if err := c.ResumeGame(123); err != nil || sock.lastEmit().event != "game/resume" {
+		t.Errorf("ResumeGame() want game/resume emitted, got %v, %v", sock.lastEmit(), err)
+	}
+}
+
+func TestClient_OnConnectionState(t *testing.T) {
+	sockets := make(chan *fakeSocket, 10)
+	c := &Client{dial: func() (socket, error) {
+		s := &fakeSocket{}
+		sockets <- s
+		return s, nil
+	}}
+	c.SetReconnectPolicy(1, time.Millisecond, time.Millisecond)
+	var mu sync.Mutex
+	var states []ConnState
+	c.OnConnectionState(func(state ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, state)
+	})
+
+	if err := c.connect(); err != nil {
+		t.Fatal(err)
+	}
+	(<-sockets).receive("disconnection", "")
+	<-sockets
+
+	want := []ConnState{Connected, Disconnected, Reconnecting, Connected}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(states) == len(want)
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("OnConnectionState want %v, got %v", want, states)
+	}
+}
+
+func TestNewClient_ReconnectPolicy(t *testing.T) {
+	c := NewClient("id", "secret")
+	if c.maxAttempts != -1 || c.baseDelay != time.Second || c.maxDelay != time.Minute {
+		t.Errorf("NewClient() want unlimited reconnect by default, got %d attempts %v..%v", c.maxAttempts, c.baseDelay, c.maxDelay)
+	}
+	c = NewClient("id", "secret", WithReconnectPolicy(0, 0, 0))
+	if c.maxAttempts != 0 {
+		t.Errorf("WithReconnectPolicy(0, 0, 0) want reconnect disabled, got %d attempts", c.maxAttempts)
+	}
+}
+
+func TestClient_RawEvents(t *testing.T) {
+	sock := &fakeSocket{acks: map[string]string{"ui-pushes/subscribe": `{"ok": true}`}}
+	c := &Client{socket: sock}
+
+	var raw json.RawMessage
+	var pinged bool
+	if err := c.On("game/123/undo_requested", func(data json.RawMessage) { raw = data }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.On("net/ping", func() { pinged = true }); err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range []any{nil, "not a func", func(_ any, n int) {}, func(int) error { return nil }} {
+		if err := c.On("game/123/move", fn); err == nil {
+			t.Errorf("On() with %T want error, got nil", fn)
+		}
+	}
+	sock.receive("game/123/undo_requested", "15")
+	sock.receive("net/ping", "{}")
+	if string(raw) != "15" || !pinged {
+		t.Errorf("On() handlers want called, got %q, %v", raw, pinged)
+	}
+
+	if err := c.Emit("game/undo/cancel", map[string]any{"game_id": 123}); err != nil || sock.lastEmit().event != "game/undo/cancel" {
+		t.Errorf("Emit() want game/undo/cancel emitted, got %v, %v", sock.lastEmit(), err)
+	}
+	res, err := c.Ack("ui-pushes/subscribe", map[string]any{"channel": "x"}, time.Second)
+	if err != nil || string(res) != `{"ok": true}` {
+		t.Errorf("Ack() want raw response, got %q, %v", res, err)
+	}
+	if _, err := c.Ack("unknown", nil, time.Millisecond); err == nil {
+		t.Errorf("Ack() without response want error, got nil")
+	}
+}
+
+func TestClient_Off(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	moves, clocks, pauses, chats := 0, 0, 0, 0
+	c.OnMove(123, func(*GameMove) { moves++ })
+	c.OnClock(123, func(*Clock) { clocks++ })
+	c.OnGamePause(123, func(bool, Timestamp) { pauses++ })
+	c.OnGameChat(456, func(*GameChat) { chats++ })
+
+	c.Off("game/123/move")
+	sock.receive("game/123/move", `{"game_id": 123}`)
+	sock.receive("game/123/clock", `{"game_id": 123}`)
+	if moves != 0 || clocks != 1 || pauses != 1 {
+		t.Errorf("Off(move) want only clock handlers called, got %d moves, %d clocks, %d pauses", moves, clocks, pauses)
+	}
+
+	c.OffGame(123)
+	sock.receive("game/123/clock", `{"game_id": 123, "paused_since": 1672531200}`)
+	sock.receive("game/456/chat", `{"channel": "main"}`)
+	if clocks != 1 || pauses != 1 || chats != 1 {
+		t.Errorf("OffGame(123) want only game 456 handlers called, got %d clocks, %d pauses, %d chats", clocks, pauses, chats)
+	}
+}
+
+func TestClient_OffGame_Repeated(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	// A long running process watching games one after another
+	for _, gameID := range []int64{1, 2, 1} {
+		moves := 0
+		if err := c.GameConnect(gameID); err != nil {
+			t.Fatal(err)
+		}
+		c.OnMove(gameID, func(*GameMove) { moves++ })
+		c.OnGameData(gameID, func(*Game) {})
+		sock.receive(fmt.Sprintf("game/%d/move", gameID), `{"move_number": 1}`)
+		if moves != 1 {
+			t.Errorf("game %d: want a move received once, got %d", gameID, moves)
+		}
+		if got := c.ActiveGameIDs(); !slices.Equal(got, []int64{gameID}) {
+			t.Errorf("ActiveGameIDs() want [%d], got %v", gameID, got)
+		}
+		c.GameDisconnect(gameID)
+		c.OffGame(gameID)
+	}
+	if got := c.ActiveGameIDs(); len(got) != 0 {
+		t.Errorf("ActiveGameIDs() want none, got %v", got)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.handlers) != 0 || len(c.buffered) != 0 || len(c.games) != 0 {
+		t.Errorf("want nothing kept, got %d handlers, %d buffered, %d games", len(c.handlers), len(c.buffered), len(c.games))
+	}
+}
+
+func TestClient_MultipleHandlers(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var logged, played []int
+	c.OnMove(123, func(*GameMove) { panic("boom") })
+	c.OnMove(123, func(m *GameMove) { logged = append(logged, m.MoveNumber) })
+	c.OnMove(123, func(m *GameMove) { played = append(played, m.MoveNumber) })
+	sub, err := c.Subscribe("game/123/move", func(json.RawMessage) { t.Error("cancelled handler called") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub.Cancel()
+	sub.Cancel()
+
+	sock.receive("game/123/move", `{"game_id": 123, "move_number": 1}`)
+	sock.receive("game/123/move", `{"game_id": 123, "move_number": 2}`)
+	if want := []int{1, 2}; !slices.Equal(logged, want) || !slices.Equal(played, want) {
+		t.Errorf("OnMove() handlers want both called with %v, got %v and %v", want, logged, played)
+	}
+}
+
+func TestClient_SubscribeContext(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var moves, chats atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := c.SubscribeContext(ctx, "game/123/move", func() { moves.Add(1) }); err != nil {
+		t.Fatal(err)
+	}
+	c.OnGameChat(123, func(*GameChat) { chats.Add(1) })
+	c.OffGameContext(ctx, 123)
+	kept := 0
+	c.OnMove(456, func(*GameMove) { kept++ })
+
+	sock.receive("game/123/move", `{"move_number": 1}`)
+	sock.receive("game/123/chat", `{}`)
+	cancel()
+	// Handlers are removed asynchronously after cancel
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.mu.Lock()
+		n := len(c.handlers["game/123/move"]) + len(c.handlers["game/123/chat"])
+		c.mu.Unlock()
+		if n == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sock.receive("game/123/move", `{"move_number": 2}`)
+	sock.receive("game/123/chat", `{}`)
+	sock.receive("game/456/move", `{"move_number": 1}`)
+	if moves.Load() != 1 || chats.Load() != 1 || kept != 1 {
+		t.Errorf("want handlers of game 123 called once before cancel and others kept, got %d moves, %d chats, %d kept", moves.Load(), chats.Load(), kept)
+	}
+}
+
+func TestClient_GameMove(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock, Token: Token{AccessToken: "token"}}
+
+	for _, tc := range []struct {
+		x, y int
+		want string
+	}{
+		{0, 0, "aa"},
+		{3, 15, "dp"},
+		{24, 24, "yy"},
+	} {
+		if err := c.GameMove(123, tc.x, tc.y); err != nil {
+			t.Fatalf("GameMove(%d, %d) want no error, got %v", tc.x, tc.y, err)
+		}
+		if got := sock.lastEmit().args.(map[string]any)["move"]; got != tc.want {
+			t.Errorf("GameMove(%d, %d) want %q, got %q", tc.x, tc.y, tc.want, got)
+		}
+	}
+
+	if err := c.PassTurn(123); err != nil {
+		t.Fatalf("PassTurn() want no error, got %v", err)
+	}
+	if got := sock.lastEmit().args.(map[string]any)["move"]; got != ".." {
+		t.Errorf("PassTurn() want \"..\", got %q", got)
+	}
+
+	n := len(sock.events())
+	for _, xy := range [][2]int{{-1, 0}, {0, -1}, {25, 0}, {0, 25}, {-2, -2}} {
+		if err := c.GameMove(123, xy[0], xy[1]); err == nil {
+			t.Errorf("GameMove(%d, %d) want error, got nil", xy[0], xy[1])
+		}
+	}
+	if len(sock.events()) != n {
+		t.Errorf("GameMove() with invalid moves want nothing emitted, got %v", sock.events()[n:])
+	}
+}
+
+func TestClient_OnGameData_AfterConnect(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "game_name": "v0"}`)
+	sock.receive("game/123/gamedata", `{"game_id": 123, "game_name": "v1"}`)
+
+	var got []string
+	if err := c.OnGameData(123, func(g *Game) { got = append(got, g.GameName) }); err != nil {
+		t.Fatalf("OnGameData() want no error, got %v", err)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "game_name": "v2"}`)
+	if want := []string{"v1", "v2"}; !slices.Equal(got, want) {
+		t.Errorf("OnGameData() after GameConnect want gamedata %v, got %v", want, got)
+	}
+
+	if err := c.GameDisconnect(123); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	c.OnGameData(123, func(g *Game) { got = append(got, g.GameName) })
+	if len(got) != 0 {
+		t.Errorf("OnGameData() after GameDisconnect want nothing replayed, got %v", got)
+	}
+}
+
+func TestClient_OnGameData_UnknownClockSystem(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var got []*Game
+	c.OnGameData(123, func(g *Game) { got = append(got, g) })
+	sock.receive("game/123/gamedata", `{"game_id": 123, "game_name": "v0", "time_control": {"system": "hourglass"}}`)
+	if len(got) != 1 || got[0].GameName != "v0" || got[0].TimeControl.System != "hourglass" {
+		t.Fatalf("OnGameData() want game with unknown clock system, got %+v", got)
+	}
+	if clock := got[0].ComputeClock(PlayerBlack); clock.System != ClockUnknown {
+		t.Errorf("ComputeClock() want %q, got %q", ClockUnknown, clock.System)
+	}
+}
+
+func TestClient_SeekGraph(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var got []SeekGraphEntry
+	if err := c.OnSeekGraph(func(entries []SeekGraphEntry) { got = entries }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SeekGraphConnect(); err != nil || sock.lastEmit().event != "seek_graph/connect" {
+		t.Fatalf("SeekGraphConnect() want seek_graph/connect emitted, got %v, %v", sock.lastEmit(), err)
+	}
+	sock.receive("seekgraph/global", `[
+		{"challenge_id": 11, "game_id": 22, "username": "bob", "ranking": 31.0, "width": 19, "height": 19,
+		 "time_control_parameters": {"system": "byoyomi", "main_time": 600, "period_time": 30, "periods": 5}},
+		{"challenge_id": 10, "delete": 1},
+		{"challenge_id": "8"},
+		{"challenge_id": 9, "delete": true}]`)
+	if len(got) != 3 || got[0].ChallengeID != 11 || got[0].TimeControl.System != ClockByoyomi || got[0].Removed() || !got[1].Removed() || !got[2].Removed() {
+		t.Errorf("OnSeekGraph() got unexpected entries %+v", got)
+	}
+
+	if err := c.SeekGraphDisconnect(); err != nil || sock.lastEmit().event != "seek_graph/disconnect" {
+		t.Errorf("SeekGraphDisconnect() want seek_graph/disconnect emitted, got %v, %v", sock.lastEmit(), err)
+	}
+}
+
+func TestClient_OnNotification(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock, Token: Token{AccessToken: "token"}}
+
+	var got *Notification
+	if err := c.OnNotification(func(n *Notification) { got = n }); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("notification", `{"id": "n2", "type": "yourMove", "game_id": 99, "move_number": 12}`)
+	if got == nil || got.Type != NotificationYourMove || got.GameID != 99 {
+		t.Errorf("OnNotification() got unexpected notification %+v", got)
+	}
+	var data struct {
+		MoveNumber int `json:"move_number"`
+	}
+	if err := got.Decode(&data); err != nil || data.MoveNumber != 12 {
+		t.Errorf("Decode() want move_number 12, got %+v, %v", data, err)
+	}
+
+	if err := c.DeleteNotification("n2"); err != nil || sock.lastEmit().event != "notification/delete" {
+		t.Errorf("DeleteNotification() want notification/delete emitted, got %v, %v", sock.lastEmit(), err)
+	}
+}
+
+func TestClient_GameConnectAck(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var reported error
+	c.OnGameError(404, func(err error) { reported = err })
+
+	for _, tc := range []struct {
+		gameID  int64
+		reply   func()
+		wantErr bool
+	}{
+		{123, func() { sock.receive("game/123/gamedata", `{"game_id": 123}`) }, false},
+		{404, func() { sock.receive("game/404/error", `"Game not found"`) }, true},
+		{456, func() {}, true}, // Timeout
+	} {
+		go func() {
+			for !slices.Contains(sock.events(), "game/connect") {
+				time.Sleep(time.Millisecond)
+			}
+			tc.reply()
+		}()
+		err := c.GameConnectAck(tc.gameID, 100*time.Millisecond)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("GameConnectAck(%d) want error %v, got %v", tc.gameID, tc.wantErr, err)
+		}
+		sock.mu.Lock()
+		sock.emits = nil
+		sock.mu.Unlock()
+	}
+	if reported == nil || !strings.Contains(reported.Error(), "Game not found") {
+		t.Errorf("OnGameError() want error reported, got %v", reported)
+	}
+}
+
+func TestClient_GameMoveAck(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, UserID: 1, socket: sock}
+	if err := c.GameMoveAck(123, 3, 3, time.Second); err == nil {
+		t.Errorf("GameMoveAck() before game data want error, got nil")
+	}
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "moves": [[3, 2, 1000], [4, 4, 1000], [2, 2, 1000], [5, 5, 1000], [4, 4, 1000]]}`)
+
+	for _, tc := range []struct {
+		x, y    int
+		reply   func()
+		wantErr string
+	}{
+		{3, 3, func() {
+			sock.receive("game/123/move", `{"game_id": 123, "move_number": 5, "move": [3, 3, 1000]}`) // Late duplicate
+			sock.receive("game/123/move", `{"game_id": 123, "move_number": 6, "move": [3, 3, 1000]}`)
+		}, ""},
+		{-1, -1, func() { sock.receive("game/123/move", `{"game_id": 123, "move_number": 7, "move": [-1, -1, 1000]}`) }, ""},
+		{3, 3, func() { sock.receive("game/123/error", `"Illegal move: stone already placed"`) }, "stone already placed"},
+		{4, 4, func() {}, "no move received"},
+		{-1, -1, func() { sock.receive("game/123/move", `{"game_id": 123, "move_number": 9, "move": [-1, -1, 1000]}`) }, "no move received"}, // Not next
+	} {
+		go func(reply func()) {
+			for !slices.Contains(sock.events(), "game/move") {
+				time.Sleep(time.Millisecond)
+			}
+			reply()
+		}(tc.reply)
+		err := c.GameMoveAck(123, tc.x, tc.y, 100*time.Millisecond)
+		if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("GameMoveAck(%d, %d) want error %q, got %v", tc.x, tc.y, tc.wantErr, err)
+		}
+		sock.mu.Lock()
+		sock.emits = nil
+		sock.mu.Unlock()
+	}
+}
+
+func TestClient_GameMoveWait_Canceled(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{Token: Token{AccessToken: "token"}, socket: sock}
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "moves": []}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.GameMoveWait(ctx, 123, 3, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("GameMoveWait() want context.Canceled, got %v", err)
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	go func() {
+		for !slices.Contains(sock.events(), "net/ping") {
+			time.Sleep(time.Millisecond)
+		}
+		sent := sock.lastEmit().args.(map[string]any)["client"].(int64)
+		// Server clock is a minute behind
+		server := time.Now().Add(-time.Minute).UnixMilli()
+		sock.receive("net/pong", fmt.Sprintf(`{"client": %d, "server": %d}`, sent-1, server)) // Stale
+		sock.receive("net/pong", fmt.Sprintf(`{"client": %d, "server": %d}`, sent, server))
+	}()
+	latency, err := c.Ping()
+	if err != nil || latency < 0 || latency > time.Second {
+		t.Fatalf("Ping() want small latency, got %v, %v", latency, err)
+	}
+
+	var clock *Clock
+	c.OnClock(123, func(cl *Clock) { clock = cl })
+	sock.receive("game/123/clock", `{"game_id": 123}`)
+	if clock == nil || clock.Drift < 59*time.Second || clock.Drift > 61*time.Second {
+		t.Errorf("OnClock() want drift of a minute applied, got %+v", clock)
+	}
+}
+
+func TestClient_OnChallenge(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var got *Challenge
+	var withdrawn []int64
+	c.OnChallenge(func(ch *Challenge) { got = ch })
+	c.OnChallengeWithdrawn(func(id int64) { withdrawn = append(withdrawn, id) })
+	sock.receive("challenge", `{"id": 77, "challenger": {"id": 7, "username": "alice", "ranking": 25.3}, "challenger_color": "black",
+		"game": {"id": 88, "width": 19, "height": 19, "ranked": true, "rules": "japanese",
+			"time_control_parameters": {"system": "fischer", "speed": "live"}}}`)
+	if got == nil || got.ID != 77 || got.GameID != 88 || got.Width != 19 || !got.Ranked || got.Challenger.Username != "alice" || got.TimeControl.System != ClockFischer {
+		t.Errorf("OnChallenge() got unexpected challenge %+v", got)
+	}
+	sock.receive("challenge/removed", `77`)
+	sock.receive("challenge/removed", `{"challenge_id": 78}`)
+	if !slices.Equal(withdrawn, []int64{77, 78}) {
+		t.Errorf("OnChallengeWithdrawn() want [77 78], got %v", withdrawn)
+	}
+}
+
+func TestClient_ReportLatency(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		c.reportLatency(tick)
+		close(done)
+	}()
+	tick <- time.Now()
+	for !slices.Contains(sock.events(), "net/ping") {
+		time.Sleep(time.Millisecond)
+	}
+	sent := sock.lastEmit().args.(map[string]any)["client"].(int64)
+	sock.receive("net/pong", fmt.Sprintf(`{"client": %d, "server": %d}`, sent, sent))
+	for !slices.Contains(sock.events(), "game/latency") {
+		time.Sleep(time.Millisecond)
+	}
+	got := sock.lastEmit().args.(map[string]any)
+	if got["game_id"] != int64(123) || got["latency"] != c.Latency().Milliseconds() {
+		t.Errorf("want game/latency of game 123 with measured latency %v, got %v", c.Latency(), got)
+	}
+
+	c.Disconnect()
+	tick <- time.Now()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("want reporter stopped after Disconnect")
+	}
+}
+
+func TestClient_OnClock_Drift(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var clock *Clock
+	c.OnClock(123, func(cl *Clock) { clock = cl })
+	// Local clock is 30s ahead of OGS, black moved 10s ago in OGS clock
+	serverNow := time.Now().Add(-30 * time.Second)
+	sock.receive("game/123/clock", fmt.Sprintf(`{"game_id": 123, "black_player_id": 1, "current_player": 1,
+		"black_time": {"thinking_time": 60}, "last_move": %d, "now": %d}`,
+		serverNow.Add(-10*time.Second).UnixMilli(), serverNow.UnixMilli()))
+	if clock == nil {
+		t.Fatal("OnClock() handler was not called")
+	}
+	tc := &TimeControl{System: ClockAbsolute}
+	if got := clock.ComputeClock(tc, PlayerBlack); got.MainTime < 49 || got.MainTime > 51 {
+		t.Errorf("ComputeClock() want 50s left despite skewed local clock, got %+v", got)
+	}
+}
+
+func TestClient_WatchClock(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+	if err := c.GameConnect(123); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "time_control": {"system": "absolute"},
+		"clock": {"black_player_id": 1, "white_player_id": 2, "current_player": 2,
+			"black_time": {"thinking_time": 300}, "white_time": {"thinking_time": 200}}}`)
+
+	var verr *ValidationError
+	if _, _, err := c.WatchClock(123, 0); !errors.As(err, &verr) {
+		t.Errorf("WatchClock(0) want ValidationError, got %v", err)
+	}
+	ch, cancel, err := c.WatchClock(123, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() *ComputedClockPair {
+		select {
+		case pair := <-ch:
+			return pair
+		case <-time.After(time.Second):
+			t.Fatal("WatchClock() want clocks sent, got nothing")
+		}
+		return nil
+	}
+	if pair := next(); pair.Black.MainTime != 300 || pair.White.System != ClockAbsolute {
+		t.Errorf("WatchClock() want clocks from gamedata, got %+v %+v", pair.Black, pair.White)
+	}
+	sock.receive("game/123/clock", `{"black_player_id": 1, "white_player_id": 2, "current_player": 1,
+		"black_time": {"thinking_time": 290}, "white_time": {"thinking_time": 200}}`)
+	if pair := next(); pair.Black.MainTime > 290 || pair.White.MainTime != 200 {
+		t.Errorf("WatchClock() want clocks from clock event, got %+v %+v", pair.Black, pair.White)
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("WatchClock() want channel closed after cancel")
+	}
+	cancel() // No-op
+}
+
+func TestClient_OnGameEvent(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var raw json.RawMessage
+	var update *GamePlayerUpdate
+	c.OnGameEvent(123, "latency", func(data json.RawMessage) { raw = data })
+	c.OnGamePlayerUpdate(123, func(u *GamePlayerUpdate) { update = u })
+	sock.receive("game/123/latency", `{"player_id": 7, "latency": 250}`)
+	sock.receive("game/123/player_update", `{"players": {"black": 7, "white": 8}, "rengo_teams": {"black": [7, 9], "white": [8]}}`)
+	if string(raw) != `{"player_id": 7, "latency": 250}` {
+		t.Errorf("OnGameEvent() want raw payload, got %s", raw)
+	}
+	if update == nil || update.Players.White != 8 || !slices.Equal(update.RengoTeams.Black, []int64{7, 9}) {
+		t.Errorf("OnGamePlayerUpdate() got unexpected update %+v", update)
+	}
+}
+
+func TestClient_OnComputedClock(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	var black, white *ComputedClock
+	c.OnComputedClock(123, func(b, w *ComputedClock) { black, white = b, w })
+	sock.receive("game/123/clock", `{"game_id": 123}`) // No time control yet
+	if black != nil {
+		t.Fatalf("want no clocks before gamedata, got %+v", black)
+	}
+	sock.receive("game/123/gamedata", `{"game_id": 123, "time_control": {"system": "byoyomi", "period_time": 30}}`)
+	// Captured from OGS, white in turn and in overtime
+	sock.receive("game/123/clock", fmt.Sprintf(`{"game_id": 123, "black_player_id": 1, "white_player_id": 2, "current_player": 2,
+		"last_move": %d, "black_time": {"thinking_time": 200, "periods": 5, "period_time": 30},
+		"white_time": {"thinking_time": 0, "periods": 1, "period_time": 30}}`, time.Now().Add(-31*time.Second).UnixMilli()))
+	if black == nil || black.MainTime != 200 || black.PeriodsLeft != 5 || black.SuddenDeath {
+		t.Errorf("want black clock computed, got %+v", black)
+	}
+	if white == nil || !white.SuddenDeath || !white.TimedOut {
+		t.Errorf("want white timed out, got %+v", white)
+	}
+}
+
+func TestClient_ConditionalMoves(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock, Token: Token{AccessToken: "token"}, UserID: 42}
+
+	var got *ConditionalMoveTree
+	if err := c.OnConditionalMoves(123, func(tree *ConditionalMoveTree) { got = tree }); err != nil {
+		t.Fatal(err)
+	}
+	sock.receive("game/123/conditional_moves", `{"player_id": 42, "move_number": 10, "moves": [null, {"pp": ["dp", {"dq": ["cq", {}]}]}]}`)
+	want := &ConditionalMoveTree{Responses: map[string]*ConditionalMoveTree{
+		"pp": {Move: "dp", Responses: map[string]*ConditionalMoveTree{
+			"dq": {Move: "cq", Responses: map[string]*ConditionalMoveTree{}},
+		}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OnConditionalMoves() want %+v, got %+v", want, got)
+	}
+
+	if err := c.SetConditionalMoves(123, 10, want); err != nil {
+		t.Fatalf("SetConditionalMoves() want no error, got %v", err)
+	}
+	emitted := sock.lastEmit()
+	data, _ := json.Marshal(emitted.args.(map[string]any)["conditional_moves"])
+	if emitted.event != "game/conditional_moves/set" || string(data) != `[null,{"pp":["dp",{"dq":["cq",{}]}]}]` {
+		t.Errorf("SetConditionalMoves() got unexpected %s %s", emitted.event, data)
+	}
+}
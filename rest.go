@@ -1,12 +1,18 @@
 package googs
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -14,7 +20,47 @@ const (
 	ogsBaseURL = "https://online-go.com"
 )
 
+// page is the envelope of paginated REST responses.
+type page[T any] struct {
+	Count    int // Total number of results across all pages
+	Next     string
+	Previous string
+	Results  []T
+}
+
+// pageParams returns the query parameters to request a page, which starts from
+// 1.
+func pageParams(pageNum, pageSize int) (url.Values, error) {
+	if pageNum < 1 || pageSize < 1 {
+		return nil, fmt.Errorf("invalid page %d with size %d", pageNum, pageSize)
+	}
+	return url.Values{
+		"page":      {strconv.Itoa(pageNum)},
+		"page_size": {strconv.Itoa(pageSize)},
+	}, nil
+}
+
+// defaultHTTPClient is used for REST requests when Client.HTTPClient is nil.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func (c *Client) base() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return ogsBaseURL
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
 func (c *Client) AboutMe() (*User, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
 	res := User{}
 	if err := c.Get("/api/v1/me", nil, &res); err != nil {
 		return nil, err
@@ -22,8 +68,511 @@ func (c *Client) AboutMe() (*User, error) {
 	return &res, nil
 }
 
+// UpdateProfile changes profile of the logged in user, the updated profile is
+// returned.
+func (c *Client) UpdateProfile(params UpdateProfileParams) (*User, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	res := User{}
+	if err := c.send("PATCH", "/api/v1/me", params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// PlayerByID fetches full profile of a player.
+func (c *Client) PlayerByID(id int64) (*User, error) {
+	res := User{}
+	if err := c.Get(fmt.Sprintf("/api/v1/players/%d", id), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// PlayerByUsername fetches full profile of a player by the exact username, an
+// *APIError with 404 status is returned when there is no such player.
+func (c *Client) PlayerByUsername(username string) (*User, error) {
+	params := url.Values{"username": {username}}
+	res := page[User]{}
+	if err := c.Get("/api/v1/players", params, &res); err != nil {
+		return nil, err
+	}
+	for i := range res.Results {
+		if res.Results[i].Username == username {
+			return &res.Results[i], nil
+		}
+	}
+	err := newAPIError(http.StatusNotFound, c.base()+"/api/v1/players?"+params.Encode(), nil)
+	err.Detail = fmt.Sprintf("player %q not found", username)
+	return nil, err
+}
+
+// SearchPlayers finds players whose username starts with query. Results are
+// paginated with page starting from 1, the total number of matched players
+// is returned as well.
+func (c *Client) SearchPlayers(query string, pageNum, pageSize int) ([]User, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Set("username", query)
+	res := page[User]{}
+	if err := c.Get("/api/v1/players", params, &res); err != nil {
+		return nil, 0, err
+	}
+	return res.Results, res.Count, nil
+}
+
+// Notifications lists pending notifications of the logged in user, only the
+// ones not marked read yet when unreadOnly is set. See also OnNotification.
+func (c *Client) Notifications(unreadOnly bool) ([]Notification, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	var res []Notification
+	if err := c.Get("/api/v1/notifications", nil, &res); err != nil {
+		return nil, err
+	}
+	if unreadOnly {
+		res = slices.DeleteFunc(res, func(n Notification) bool { return n.Read })
+	}
+	return res, nil
+}
+
+// MarkNotificationRead marks a notification read, unlike DeleteNotification
+// it is kept.
+func (c *Client) MarkNotificationRead(id string) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.send("PATCH", "/api/v1/notifications/"+url.PathEscape(id), map[string]any{"read": true}, nil)
+}
+
+// Friends lists all friends of the logged in user.
+func (c *Client) Friends() ([]User, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	return allPages[User](c, "/api/v1/me/friends")
+}
+
+// allPages fetches results from all pages of a paginated resource.
+func allPages[T any](c *Client, uri string) ([]T, error) {
+	var results []T
+	for pageNum := 1; ; pageNum++ {
+		params, _ := pageParams(pageNum, 100)
+		res := page[T]{}
+		if err := c.Get(uri, params, &res); err != nil {
+			return nil, err
+		}
+		results = append(results, res.Results...)
+		if res.Next == "" {
+			return results, nil
+		}
+	}
+}
+
+// AddFriend adds the player to friends of the logged in user.
+func (c *Client) AddFriend(playerID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.Post("/api/v1/me/friends", map[string]any{"player_id": playerID}, nil)
+}
+
+// RemoveFriend removes the player from friends of the logged in user.
+func (c *Client) RemoveFriend(playerID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.send("DELETE", "/api/v1/me/friends", map[string]any{"player_id": playerID}, nil)
+}
+
+// BlockedUsers lists all players blocked by the logged in user.
+func (c *Client) BlockedUsers() ([]User, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	return allPages[User](c, "/api/v1/me/blocks")
+}
+
+// BlockUser blocks challenges and messages from the player. Blocking a player
+// already blocked fails with a 409 *APIError, see IsConflict.
+func (c *Client) BlockUser(playerID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.Post(fmt.Sprintf("/api/v1/players/%d/block", playerID), struct{}{}, nil)
+}
+
+// UnblockUser unblocks the player blocked via BlockUser.
+func (c *Client) UnblockUser(playerID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.Post(fmt.Sprintf("/api/v1/players/%d/unblock", playerID), struct{}{}, nil)
+}
+
+var reportReasons = []ReportReason{ReportStalling, ReportSandbagging, ReportAbusive, ReportCheating}
+
+// ReportPlayer reports a player to moderators for the behavior in a game,
+// with notes explaining it. A *ValidationError is returned for an unknown
+// reason.
+func (c *Client) ReportPlayer(playerID, gameID int64, reason ReportReason, notes string) error {
+	if !slices.Contains(reportReasons, reason) {
+		var allowed []string
+		for _, r := range reportReasons {
+			allowed = append(allowed, string(r))
+		}
+		return &ValidationError{Field: "reason", Value: string(reason), Allowed: allowed}
+	}
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.Post("/api/v1/report", map[string]any{
+		"reported_user_id": playerID,
+		"reported_game":    gameID,
+		"report_type":      reason,
+		"reporter_note":    notes,
+	}, nil)
+}
+
+// GameHistory fetches games of a player, most recent first. Results are
+// paginated with page starting from 1, iterate until HasNext() is false to
+// enumerate all games.
+func (c *Client) GameHistory(playerID int64, pageNum, pageSize int) (*GameHistoryPage, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("ordering", "-id")
+
+	res := page[restGame]{}
+	if err := c.Get(fmt.Sprintf("/api/v1/players/%d/games", playerID), params, &res); err != nil {
+		return nil, err
+	}
+
+	history := &GameHistoryPage{
+		Total:    res.Count,
+		Page:     pageNum,
+		Next:     res.Next,
+		Previous: res.Previous,
+	}
+	for _, g := range res.Results {
+		history.Results = append(history.Results, g.entry())
+	}
+	return history, nil
+}
+
+// restPlayer is a player in restGame.
+type restPlayer struct {
+	ID           int64
+	Username     string
+	Professional bool
+	Ranking      float32
+}
+
+func (p restPlayer) player() Player {
+	return Player{ID: p.ID, Username: p.Username, Professional: p.Professional, Rank: p.Ranking}
+}
+
+// restGame is a game entry in REST game lists, which differs from realtime
+// ones, e.g. "black" is the player ID and player "ranking" is not named
+// "rank".
+type restGame struct {
+	ID         int64
+	Name       string
+	Width      int
+	Height     int
+	Ended      *string
+	Ranked     bool
+	Handicap   int
+	Komi       json.RawMessage // A string like "6.50"
+	Tournament int64
+	Ladder     int64
+	Players    struct {
+		Black restPlayer
+		White restPlayer
+	}
+}
+
+func (g *restGame) entry() GameListEntry {
+	komi, _ := strconv.ParseFloat(strings.Trim(string(g.Komi), `"`), 32)
+	return GameListEntry{
+		ID:           g.ID,
+		Name:         g.Name,
+		Width:        g.Width,
+		Height:       g.Height,
+		Phase:        cond(g.Ended != nil, FinishedPhase, PlayPhase),
+		Ranked:       g.Ranked,
+		Handicap:     g.Handicap,
+		Komi:         float32(komi),
+		TournamentID: g.Tournament,
+		LadderID:     g.Ladder,
+		Black:        g.Players.Black.player(),
+		White:        g.Players.White.player(),
+	}
+}
+
+// MyGameHistory fetches games of the logged in user, see GameHistory.
+func (c *Client) MyGameHistory(pageNum, pageSize int) (*GameHistoryPage, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	return c.GameHistory(c.UserID, pageNum, pageSize)
+}
+
+var (
+	ratingSpeeds = []string{"overall", "blitz", "rapid", "live", "correspondence"}
+	ratingSizes  = []string{"0", "9", "13", "19"} // "0" for all sizes
+)
+
+// PlayerRatingHistory fetches rating history of a player for games of the
+// speed ("overall", "blitz", "rapid", "live" or "correspondence") and board
+// size ("9", "13", "19", or "0" for all), a *ValidationError is returned for
+// unknown values.
+func (c *Client) PlayerRatingHistory(playerID int64, speed, size string) (*RatingHistory, error) {
+	if !slices.Contains(ratingSpeeds, speed) {
+		return nil, &ValidationError{Field: "speed", Value: speed, Allowed: ratingSpeeds}
+	}
+	if !slices.Contains(ratingSizes, size) {
+		return nil, &ValidationError{Field: "size", Value: size, Allowed: ratingSizes}
+	}
+	params := url.Values{"speed": {speed}, "size": {size}}
+	res := RatingHistory{}
+	if err := c.Get(fmt.Sprintf("/api/v1/players/%d/ratings", playerID), params, &res.Entries); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+var (
+	leaderboardSizes  = []string{"9", "13", "19"}
+	leaderboardSpeeds = []string{"blitz", "live", "correspondence"}
+)
+
+// Leaderboards lists top ranked players of games of the board size (9, 13 or
+// 19) and speed ("blitz", "live" or "correspondence"), a *ValidationError is
+// returned for unknown values. Results are paginated with page starting from
+// 1, the total number of ranked players is returned as well.
+func (c *Client) Leaderboards(size int, speed string, pageNum, pageSize int) ([]LeaderboardEntry, int, error) {
+	if !slices.Contains(leaderboardSizes, strconv.Itoa(size)) {
+		return nil, 0, &ValidationError{Field: "size", Value: strconv.Itoa(size), Allowed: leaderboardSizes}
+	}
+	if !slices.Contains(leaderboardSpeeds, speed) {
+		return nil, 0, &ValidationError{Field: "speed", Value: speed, Allowed: leaderboardSpeeds}
+	}
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Set("size", strconv.Itoa(size))
+	params.Set("speed", speed)
+	res := page[LeaderboardEntry]{}
+	if err := c.Get("/api/v1/leaderboards", params, &res); err != nil {
+		return nil, 0, err
+	}
+	return res.Results, res.Count, nil
+}
+
+// Tournaments lists tournaments, results are paginated with page starting
+// from 1. The total number of tournaments is returned as well.
+func (c *Client) Tournaments(pageNum, pageSize int) ([]Tournament, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := page[Tournament]{}
+	if err := c.Get("/api/v1/tournaments", params, &res); err != nil {
+		return nil, 0, err
+	}
+	return res.Results, res.Count, nil
+}
+
+// TournamentGames lists games of a tournament, optionally of a round only (0
+// for all rounds). Results are paginated with page starting from 1, the total
+// number of games is returned as well.
+func (c *Client) TournamentGames(tournamentID int64, pageNum, pageSize, round int) ([]GameListEntry, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if round < 0 {
+		return nil, 0, &ValidationError{Field: "round", Value: strconv.Itoa(round)}
+	}
+	if round > 0 {
+		params.Set("round", strconv.Itoa(round))
+	}
+	res := page[restGame]{}
+	if err := c.Get(fmt.Sprintf("/api/v1/tournaments/%d/games", tournamentID), params, &res); err != nil {
+		return nil, 0, err
+	}
+	var games []GameListEntry
+	for _, g := range res.Results {
+		games = append(games, g.entry())
+	}
+	return games, res.Count, nil
+}
+
+// TournamentByID fetches a tournament, e.g. GameListEntry.TournamentID.
+func (c *Client) TournamentByID(id int64) (*Tournament, error) {
+	res := Tournament{}
+	if err := c.Get(fmt.Sprintf("/api/v1/tournaments/%d", id), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Ladders lists ladders, results are paginated with page starting from 1.
+// The total number of ladders is returned as well.
+func (c *Client) Ladders(pageNum, pageSize int) ([]Ladder, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := page[Ladder]{}
+	if err := c.Get("/api/v1/ladders", params, &res); err != nil {
+		return nil, 0, err
+	}
+	return res.Results, res.Count, nil
+}
+
+// LadderByID fetches a ladder, e.g. GameListEntry.LadderID.
+func (c *Client) LadderByID(id int64) (*Ladder, error) {
+	res := Ladder{}
+	if err := c.Get(fmt.Sprintf("/api/v1/ladders/%d", id), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// LadderPlayers lists standings of a ladder, top position first. Results are
+// paginated with page starting from 1, the total number of players in the
+// ladder is returned as well.
+func (c *Client) LadderPlayers(ladderID int64, pageNum, pageSize int) ([]LadderPlayer, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.ladderPlayers(ladderID, params)
+}
+
+// MyLadderPosition fetches standing of the logged in user in a ladder, an
+// *APIError with 404 status is returned when the user has not joined it.
+func (c *Client) MyLadderPosition(ladderID int64) (*LadderPlayer, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	params := url.Values{"player_id": {strconv.FormatInt(c.UserID, 10)}}
+	players, _, err := c.ladderPlayers(ladderID, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range players {
+		if players[i].UserID == c.UserID {
+			return &players[i], nil
+		}
+	}
+	uri := fmt.Sprintf("/api/v1/ladders/%d/players", ladderID)
+	notFound := newAPIError(http.StatusNotFound, c.base()+uri+"?"+params.Encode(), nil)
+	notFound.Detail = fmt.Sprintf("player %d not in ladder %d", c.UserID, ladderID)
+	return nil, notFound
+}
+
+func (c *Client) ladderPlayers(ladderID int64, params url.Values) ([]LadderPlayer, int, error) {
+	res := page[restLadderPlayer]{}
+	if err := c.Get(fmt.Sprintf("/api/v1/ladders/%d/players", ladderID), params, &res); err != nil {
+		return nil, 0, err
+	}
+	var players []LadderPlayer
+	for _, p := range res.Results {
+		players = append(players, p.ladderPlayer())
+	}
+	return players, res.Count, nil
+}
+
+// restLadderPlayer is an entry in ladder standings, where the player is
+// nested and challenges are listed in full.
+type restLadderPlayer struct {
+	Rank               int
+	Player             restPlayer
+	IncomingChallenges []json.RawMessage `json:"incoming_challenges"`
+	OutgoingChallenges []json.RawMessage `json:"outgoing_challenges"`
+}
+
+func (p restLadderPlayer) ladderPlayer() LadderPlayer {
+	return LadderPlayer{
+		UserID:                 p.Player.ID,
+		Username:               p.Player.Username,
+		Ranking:                p.Player.Ranking,
+		IncomingChallengeCount: len(p.IncomingChallenges),
+		OutgoingChallengeCount: len(p.OutgoingChallenges),
+		LadderPosition:         p.Rank,
+	}
+}
+
+// Groups lists groups, results are paginated with page starting from 1. The
+// total number of groups is returned as well.
+func (c *Client) Groups(pageNum, pageSize int) ([]Group, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := page[Group]{}
+	if err := c.Get("/api/v1/groups", params, &res); err != nil {
+		return nil, 0, err
+	}
+	return res.Results, res.Count, nil
+}
+
+// GroupByID fetches a group.
+func (c *Client) GroupByID(id int64) (*Group, error) {
+	res := Group{}
+	if err := c.Get(fmt.Sprintf("/api/v1/groups/%d", id), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GroupMembers lists members of a group, results are paginated with page
+// starting from 1. The total number of members is returned as well.
+func (c *Client) GroupMembers(groupID int64, pageNum, pageSize int) ([]GroupMember, int, error) {
+	params, err := pageParams(pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := page[restGroupMember]{}
+	if err := c.Get(fmt.Sprintf("/api/v1/groups/%d/members", groupID), params, &res); err != nil {
+		return nil, 0, err
+	}
+	var members []GroupMember
+	for _, m := range res.Results {
+		members = append(members, GroupMember{Player: m.User.player(), JoinedAt: m.Joined})
+	}
+	return members, res.Count, nil
+}
+
+// restGroupMember is an entry in group members, where the player is nested.
+type restGroupMember struct {
+	User   restPlayer
+	Joined Timestamp
+}
+
+// Announcements lists site-wide announcements currently active.
+func (c *Client) Announcements() ([]Announcement, error) {
+	var res []Announcement
+	if err := c.Get("/api/v1/announcements", nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // Overview returns active games.
 func (c *Client) Overview() (*Overview, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
 	res := Overview{}
 	if err := c.Get("/api/v1/ui/overview", nil, &res); err != nil {
 		return nil, err
@@ -36,18 +585,39 @@ func (c *Client) Game(gameID int64) (*Game, error) {
 	// NOTE: /termination-api/game/:ID does not work for private games, so
 	// use the tradional API here with a temporary struct.
 	gameT := struct {
-		Game `json:"gamedata"` // Embedded
+		Game Game `json:"gamedata"`
 	}{}
 	if err := c.Get(fmt.Sprintf("/api/v1/games/%d", gameID), nil, &gameT); err != nil {
 		return nil, err
 	}
 	res := &gameT.Game
+	res.baseURL = c.baseURL
 	if res.Height <= 0 || res.Width <= 0 || res.Height != res.Width {
 		return nil, fmt.Errorf("invalid Board dimension %d x %d", res.Width, res.Height)
 	}
 	return res, nil
 }
 
+// GameSGF downloads the game record in SGF format as generated by OGS.
+func (c *Client) GameSGF(gameID int64) ([]byte, error) {
+	var body []byte
+	var header http.Header
+	err := c.retryUnauthorized(func() (err error) {
+		body, header, err = c.ogsGet(fmt.Sprintf("/api/v1/games/%d/sgf", gameID), nil)
+		return err
+	})
+	if statusCode(err) == http.StatusNotFound {
+		return nil, fmt.Errorf("game %d does not exist: %w", gameID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download SGF of game %d: %w", gameID, err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType != "application/x-go-sgf" {
+		return nil, fmt.Errorf("SGF of game %d is not available (not finished yet?), got content type %q", gameID, header.Get("Content-Type"))
+	}
+	return body, nil
+}
+
 // GameState fetches current game information with board spanshot.
 func (c *Client) GameState(gameID int64) (*GameState, error) {
 	res := GameState{}
@@ -63,13 +633,213 @@ func (c *Client) GameState(gameID int64) (*GameState, error) {
 	return &res, nil
 }
 
-// Get sends a GET request.
+var challengeColors = []string{"automatic", "random", "black", "white"}
+
+// CreateChallenge posts an open challenge (a.k.a. seek) for anyone to accept,
+// or a challenge to the player when PlayerID is set. A *ValidationError is
+// returned for an unknown Color.
+func (c *Client) CreateChallenge(req *ChallengeRequest) (*ChallengeResponse, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	color := cond(req.Color == "", "automatic", req.Color)
+	if !slices.Contains(challengeColors, color) {
+		return nil, &ValidationError{Field: "color", Value: req.Color, Allowed: challengeColors}
+	}
+	data := restChallenge{ChallengerColor: color, MinRanking: -1000, MaxRanking: 1000}
+	data.Game.Name = req.Name
+	data.Game.Rules = req.Rules.orDefault()
+	data.Game.Ranked = req.Ranked
+	data.Game.Width = req.Size
+	data.Game.Height = req.Size
+	data.Game.Handicap = req.Handicap
+	data.Game.KomiAuto = cond(req.Komi == nil, "automatic", "custom")
+	data.Game.Komi = req.Komi
+	data.Game.PauseOnWeekends = req.TimeControl.PauseOnWeekends
+	data.Game.TimeControl = req.TimeControl.System
+	data.Game.TimeControlParameters.System = req.TimeControl.System
+	data.Game.TimeControlParameters.TimeControl = req.TimeControl
+
+	uri := "/api/v1/challenges"
+	if req.PlayerID != 0 {
+		uri = fmt.Sprintf("/api/v1/players/%d/challenge", req.PlayerID)
+	}
+	res := ChallengeResponse{}
+	if err := c.Post(uri, data, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WithdrawChallenge cancels a challenge created via CreateChallenge which is
+// not accepted yet.
+func (c *Client) WithdrawChallenge(challengeID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.Delete(fmt.Sprintf("/api/v1/challenges/%d", challengeID))
+}
+
+// restChallenge is the payload to create a challenge.
+type restChallenge struct {
+	ChallengerColor string `json:"challenger_color"`
+	MinRanking      int    `json:"min_ranking"`
+	MaxRanking      int    `json:"max_ranking"`
+	Game            struct {
+		Name                  string      `json:"name"`
+		Rules                 RuleSet     `json:"rules"`
+		Ranked                bool        `json:"ranked"`
+		Width                 int         `json:"width"`
+		Height                int         `json:"height"`
+		Handicap              int         `json:"handicap"`
+		KomiAuto              string      `json:"komi_auto"`
+		Komi                  *float32    `json:"komi"`
+		PauseOnWeekends       bool        `json:"pause_on_weekends"`
+		TimeControl           ClockSystem `json:"time_control"`
+		TimeControlParameters struct {
+			System ClockSystem `json:"time_control"` // Both keys are expected
+			TimeControl
+		} `json:"time_control_parameters"`
+	} `json:"game"`
+}
+
+// ListChallenges lists challenges sent or received by the logged in user.
+func (c *Client) ListChallenges() ([]Challenge, error) {
+	return c.challenges(nil)
+}
+
+// IncomingChallenges lists challenges received by the logged in user, to
+// accept via AcceptChallenge or decline via RejectChallenge.
+func (c *Client) IncomingChallenges() ([]Challenge, error) {
+	return c.challenges(url.Values{"direction": {"received"}})
+}
+
+func (c *Client) challenges(params url.Values) ([]Challenge, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	res := page[restChallengeEntry]{}
+	if err := c.Get("/api/v1/me/challenges", params, &res); err != nil {
+		return nil, err
+	}
+	var challenges []Challenge
+	for _, ch := range res.Results {
+		challenges = append(challenges, ch.challenge())
+	}
+	return challenges, nil
+}
+
+// AcceptChallenge accepts a challenge sent to the logged in user, the game
+// started is returned.
+func (c *Client) AcceptChallenge(challengeID int64) (*Game, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	var res struct {
+		Game int64
+	}
+	if err := c.Post(fmt.Sprintf("/api/v1/me/challenges/%d/accept", challengeID), struct{}{}, &res); err != nil {
+		return nil, err
+	}
+	return c.Game(res.Game)
+}
+
+// RejectChallenge declines a challenge sent to the logged in user.
+func (c *Client) RejectChallenge(challengeID int64) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	return c.Delete(fmt.Sprintf("/api/v1/me/challenges/%d", challengeID))
+}
+
+// restChallengeEntry is a challenge in REST challenge lists, where game
+// settings are nested.
+type restChallengeEntry struct {
+	ID              int64
+	Challenger      restPlayer
+	Challenged      restPlayer
+	ChallengerColor string `json:"challenger_color"`
+	Game            struct {
+		ID          int64
+		Name        string
+		Width       int
+		Height      int
+		Ranked      bool
+		Handicap    int
+		Rules       RuleSet
+		TimeControl TimeControl `json:"time_control_parameters"`
+	}
+}
+
+func (ch *restChallengeEntry) challenge() Challenge {
+	return Challenge{
+		ID:          ch.ID,
+		Challenger:  ch.Challenger.player(),
+		Challenged:  ch.Challenged.player(),
+		GameID:      ch.Game.ID,
+		Name:        ch.Game.Name,
+		Width:       ch.Game.Width,
+		Height:      ch.Game.Height,
+		Ranked:      ch.Game.Ranked,
+		Handicap:    ch.Game.Handicap,
+		Rules:       ch.Game.Rules,
+		Color:       ch.ChallengerColor,
+		TimeControl: ch.Game.TimeControl,
+	}
+}
+
+// Get sends a GET request. When OGS rejects the access token with 401 and
+// AutoRefresh is set, the token is refreshed and the request is retried once
+// transparently.
 func (c *Client) Get(uri string, params url.Values, ptr any) error {
+	return c.retryUnauthorized(func() error {
+		return c.get(uri, params, ptr)
+	})
+}
+
+// retryUnauthorized calls fn, refreshes the token and calls fn again when fn
+// fails with 401 and AutoRefresh is set.
+func (c *Client) retryUnauthorized(fn func() error) error {
+	stale := c.accessToken()
+	err := fn()
+	if !c.AutoRefresh || !IsUnauthorized(err) {
+		return err
+	}
+	if rerr := c.refreshUnauthorized(stale); rerr != nil {
+		return fmt.Errorf("%w, token refresh failed: %v", err, rerr)
+	}
+	return fn()
+}
+
+// Post sends a POST request with data encoded in JSON, the response is decoded
+// into ptr unless it is nil. Unauthorized requests are retried the same way as
+// Get.
+func (c *Client) Post(uri string, data any, ptr any) error {
+	return c.send("POST", uri, data, ptr)
+}
+
+// Delete sends a DELETE request, unauthorized requests are retried the same
+// way as Get.
+func (c *Client) Delete(uri string) error {
+	return c.send("DELETE", uri, nil, nil)
+}
+
+func (c *Client) send(method, uri string, data any, ptr any) error {
+	return c.retryUnauthorized(func() error {
+		body, err := c.ogsSend(method, uri, data)
+		if err != nil || ptr == nil {
+			return err
+		}
+		return json.Unmarshal(body, ptr)
+	})
+}
+
+func (c *Client) get(uri string, params url.Values, ptr any) error {
 	if reflect.ValueOf(ptr).Kind() != reflect.Ptr {
 		return fmt.Errorf("ptr argument must be a pointer, got %T", ptr)
 	}
 
-	body, err := ogsGet(uri, c.AccessToken, params)
+	body, _, err := c.ogsGet(uri, params)
 	if err != nil {
 		return err
 	}
@@ -79,48 +849,146 @@ func (c *Client) Get(uri string, params url.Values, ptr any) error {
 	return nil
 }
 
-func ogsGet(uri string, accessToken string, params url.Values) ([]byte, error) {
-	url := ogsBaseURL + uri
+func (c *Client) ogsGet(uri string, params url.Values) ([]byte, http.Header, error) {
+	url := c.base() + uri
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if token := c.accessToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
-	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 	req.URL.RawQuery = params.Encode()
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	c.debug("REST request", "method", req.Method, "uri", uri, "params", redacted(params))
+	resp, err := c.do(req, uri)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s -> %s", url, resp.Status)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s -> %w", url, err)
 	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, newAPIError(resp.StatusCode, url, body)
+	}
+	return body, resp.Header, nil
+}
+
+func (c *Client) ogsPost(uri string, data url.Values) ([]byte, error) {
+	req, err := http.NewRequest("POST", c.base()+uri, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to post %q: %v", uri, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	c.debug("REST request", "method", req.Method, "uri", uri, "data", redacted(data))
+	resp, err := c.do(req, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post %q: %v", uri, err)
+	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("%s -> %w", url, err)
+		return nil, fmt.Errorf("failed to read response of %q: %v", uri, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp.StatusCode, c.base()+uri, body)
 	}
 	return body, nil
 }
 
-func ogsPost(uri string, data url.Values) ([]byte, error) {
-	resp, err := http.PostForm(ogsBaseURL+uri, data)
+// ogsSend sends a request with data encoded in JSON, no body is sent when data
+// is nil.
+func (c *Client) ogsSend(method, uri string, data any) ([]byte, error) {
+	var payload io.Reader
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(b)
+	}
+	url := c.base() + uri
+	req, err := http.NewRequest(method, url, payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to post %q: %v", uri, err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	if token := c.accessToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server responded %q for %q", resp.Status, uri)
+	c.debug("REST request", "method", req.Method, "uri", uri, "data", redacted(data))
+	resp, err := c.do(req, uri)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response of %q: %v", uri, err)
+		return nil, fmt.Errorf("%s -> %w", url, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp.StatusCode, url, body)
 	}
 	return body, nil
 }
+
+// do sends the request subject to the rate limit. When OGS responds 429 Too
+// Many Requests or 503 Service Unavailable, the request is retried up to
+// MaxRetries times after the Retry-After delay, or with exponential backoff
+// from 1s when the header is absent. A rate limited client retries a 429 at
+// least once. The response is returned as is when Retry-After exceeds
+// maxRetryDelay, backoff is capped at it.
+func (c *Client) do(req *http.Request, uri string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		l := c.rateLimiter()
+		if l != nil {
+			l.wait()
+		}
+		start := time.Now()
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		c.debug("REST response", "method", req.Method, "uri", uri, "status", resp.StatusCode, "latency", time.Since(start))
+		retries := c.MaxRetries
+		if resp.StatusCode == http.StatusTooManyRequests && l != nil {
+			retries = max(retries, 1)
+		}
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if !retryable || attempt >= retries {
+			return resp, nil
+		}
+
+		delay := retryAfter(resp.Header, min(time.Second<<min(attempt, 6), maxRetryDelay))
+		if delay > maxRetryDelay {
+			return resp, nil // Not worth blocking the caller
+		}
+		c.debug("Retrying REST request", "method", req.Method, "uri", uri, "status", resp.StatusCode, "delay", delay)
+		resp.Body.Close()
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+		time.Sleep(delay)
+	}
+}
+
+// rewind returns a copy of the sent request with a fresh body to resend.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
@@ -0,0 +1,820 @@
+package googs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// redirectTransport sends every request to the target server regardless of
+// the requested host.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestClient returns a Client whose REST requests are served by handler.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &Client{
+		Token:       Token{AccessToken: "token"},
+		HTTPClient:  &http.Client{Transport: &redirectTransport{target}},
+		AutoRefresh: true,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestClient_HTTPClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization header want %q, got %q", "Bearer token", got)
+		}
+		writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.Identify(); err != nil {
+		t.Fatalf("Identify() want no error, got %v", err)
+	}
+	if c.Username != "alice" || c.UserID != 1 {
+		t.Errorf("Identify() want alice (1), got %s (%d)", c.Username, c.UserID)
+	}
+}
+
+func TestClient_HTTPClient_Authenticate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "refresh_token" {
+			t.Errorf("unexpected token request %s %v", r.Method, r.Form)
+		}
+		writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"user_jwt": "jwt"})
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "old"
+
+	if err := c.refreshToken(); err != nil {
+		t.Fatalf("refreshToken() want no error, got %v", err)
+	}
+	if c.AccessToken != "new" || c.RefreshToken != "refresh" || c.UserJWT != "jwt" {
+		t.Errorf("refreshToken() got unexpected credentials %+v %+v", c.Token, c.Auth)
+	}
+}
+
+func TestClient_Get_APIError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/games/404", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+	})
+	c := newTestClient(t, mux)
+
+	_, err := c.Game(404)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Game() want *APIError, got %#v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Status != "404 Not Found" || string(apiErr.Body) != `{"detail": "Not found."}` || apiErr.Detail != "Not found." {
+		t.Errorf("Game() got unexpected APIError %+v", apiErr)
+	}
+	if want := "https://online-go.com/api/v1/games/404 -> 404 Not Found: Not found."; err.Error() != want {
+		t.Errorf("Game() want error %q, got %q", want, err)
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		body       string
+		wantDetail string
+	}{
+		{"detail", `{"detail": "Authentication credentials were not provided."}`, "Authentication credentials were not provided."},
+		{"error", `{"error": "invalid_grant"}`, "invalid_grant"},
+		{"not json", `<html>Bad Gateway</html>`, ""},
+		{"empty", ``, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newAPIError(http.StatusUnauthorized, "url", []byte(tc.body))
+			if got.Detail != tc.wantDetail {
+				t.Errorf("newAPIError(%q) want Detail %q, got %q", tc.body, tc.wantDetail, got.Detail)
+			}
+		})
+	}
+}
+
+func TestClient_PlayerByID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/players/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"id": 7, "username": "alice", "ranking": 25})
+	})
+	mux.HandleFunc("/api/v1/players/8", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+	})
+	c := newTestClient(t, mux)
+
+	got, err := c.PlayerByID(7)
+	if err != nil || got.ID != 7 || got.Username != "alice" {
+		t.Errorf("PlayerByID(7) want alice, got %+v, %v", got, err)
+	}
+	if _, err := c.PlayerByID(8); statusCode(err) != http.StatusNotFound {
+		t.Errorf("PlayerByID(8) want APIError 404, got %v", err)
+	}
+}
+
+func TestClient_PlayerByUsername(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/players", func(w http.ResponseWriter, r *http.Request) {
+		var results []any
+		if r.URL.Query().Get("username") == "alice" {
+			results = append(results, map[string]any{"id": 7, "username": "alice"})
+		}
+		writeJSON(w, map[string]any{"count": len(results), "results": results})
+	})
+	c := newTestClient(t, mux)
+
+	got, err := c.PlayerByUsername("alice")
+	if err != nil || got.ID != 7 {
+		t.Errorf("PlayerByUsername(alice) want player 7, got %+v, %v", got, err)
+	}
+	if _, err := c.PlayerByUsername("nobody"); statusCode(err) != http.StatusNotFound {
+		t.Errorf("PlayerByUsername(nobody) want APIError 404, got %v", err)
+	}
+}
+
+func TestClient_Get_Anonymous(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/players/7", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("anonymous request want no Authorization header, got %q", got)
+		}
+		writeJSON(w, map[string]any{"id": 7, "username": "alice"})
+	})
+	c := newTestClient(t, mux)
+	c.Token = Token{}
+
+	if _, err := c.PlayerByID(7); err != nil {
+		t.Errorf("PlayerByID() want no error, got %v", err)
+	}
+}
+
+func TestClient_SearchPlayers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/players", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("username") != "al" || q.Get("page") != "2" || q.Get("page_size") != "2" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+		  "count": 5,
+		  "next": "https://online-go.com/api/v1/players?page=3&page_size=2&username=al",
+		  "previous": "https://online-go.com/api/v1/players?page_size=2&username=al",
+		  "results": [
+		    {"id": 3, "username": "alan", "country": "gb", "ranking": 28.5, "professional": false, "ui_class": ""},
+		    {"id": 4, "username": "alice", "country": "us", "ranking": 25, "professional": false, "ui_class": "supporter"}
+		  ]
+		}`))
+	})
+	c := newTestClient(t, mux)
+
+	got, total, err := c.SearchPlayers("al", 2, 2)
+	if err != nil {
+		t.Fatalf("SearchPlayers() want no error, got %v", err)
+	}
+	if total != 5 || len(got) != 2 || got[0].Username != "alan" || got[1].UIClass != "supporter" {
+		t.Errorf("SearchPlayers() got unexpected %d results %+v", total, got)
+	}
+	if _, _, err := c.SearchPlayers("al", 0, 2); err == nil {
+		t.Errorf("SearchPlayers() with page 0 want error, got nil")
+	}
+}
+
+func TestClient_GameHistory(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/players/7/games", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("page") != "1" || q.Get("page_size") != "10" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+		  "count": 42,
+		  "next": "https://online-go.com/api/v1/players/7/games?page=2&page_size=10",
+		  "previous": null,
+		  "results": [
+		    {
+		      "id": 1001, "name": "Friendly Match", "width": 19, "height": 19,
+		      "black": 7, "white": 8, "ended": "2024-05-01T10:00:00Z", "ranked": true,
+		      "handicap": 0, "komi": "6.50",
+		      "players": {
+		        "black": {"id": 7, "username": "alice", "ranking": 25.3, "professional": false},
+		        "white": {"id": 8, "username": "bob", "ranking": 31.0, "professional": false}
+		      }
+		    },
+		    {
+		      "id": 1002, "name": "Ongoing", "width": 9, "height": 9,
+		      "black": 8, "white": 7, "ended": null, "ranked": false, "handicap": 2, "komi": "0.50",
+		      "players": {
+		        "black": {"id": 8, "username": "bob", "ranking": 31.0},
+		        "white": {"id": 7, "username": "alice", "ranking": 25.3}
+		      }
+		    }
+		  ]
+		}`))
+	})
+	c := newTestClient(t, mux)
+	c.UserID = 7
+
+	got, err := c.MyGameHistory(1, 10)
+	if err != nil {
+		t.Fatalf("MyGameHistory() want no error, got %v", err)
+	}
+	if got.Total != 42 || got.Page != 1 || len(got.Results) != 2 || !got.HasNext() || got.Previous != "" {
+		t.Fatalf("MyGameHistory() got unexpected page %+v", got)
+	}
+	g := got.Results[0]
+	if g.ID != 1001 || g.Phase != FinishedPhase || g.Komi != 6.5 || g.Black.Username != "alice" || g.White.Ranking() != "2d" {
+		t.Errorf("MyGameHistory() got unexpected game %+v", g)
+	}
+	if g := got.Results[1]; g.Phase != PlayPhase || g.Handicap != 2 {
+		t.Errorf("MyGameHistory() got unexpected game %+v", g)
+	}
+}
+
+func TestClient_GameSGF(t *testing.T) {
+	const sgf = "(;FF[4]GM[1]SZ[9];B[cg];W[gc])"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/games/1/sgf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-go-sgf; charset=utf-8")
+		w.Write([]byte(sgf))
+	})
+	mux.HandleFunc("/api/v1/games/2/sgf", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"detail": "Game is not finished"})
+	})
+	mux.HandleFunc("/api/v1/games/3/sgf", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	c := newTestClient(t, mux)
+
+	got, err := c.GameSGF(1)
+	if err != nil || string(got) != sgf {
+		t.Errorf("GameSGF(1) want %q, got %q, %v", sgf, got, err)
+	}
+	if _, err := c.GameSGF(2); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("GameSGF(2) want content type error, got %v", err)
+	}
+	if _, err := c.GameSGF(3); statusCode(err) != http.StatusNotFound || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("GameSGF(3) want APIError 404, got %v", err)
+	}
+}
+
+func TestClient_PlayerRatingHistory(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/players/7/ratings", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("speed") != "correspondence" || q.Get("size") != "19" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+		  {"ended": 1672531200, "rating": 1650.5, "deviation": 65.2, "won": true},
+		  {"ended": 1672617600, "rating": 1638.1, "deviation": 64.9, "won": false}
+		]`))
+	})
+	c := newTestClient(t, mux)
+
+	got, err := c.PlayerRatingHistory(7, "correspondence", "19")
+	if err != nil {
+		t.Fatalf("PlayerRatingHistory() want no error, got %v", err)
+	}
+	if len(got.Entries) != 2 || !got.Entries[0].Won || got.Entries[1].Rating != 1638.1 || got.Entries[0].Ended.Unix() != 1672531200 {
+		t.Errorf("PlayerRatingHistory() got unexpected history %+v", got)
+	}
+
+	for _, args := range [][2]string{{"fast", "19"}, {"blitz", "21"}} {
+		var verr *ValidationError
+		if _, err := c.PlayerRatingHistory(7, args[0], args[1]); !errors.As(err, &verr) {
+			t.Errorf("PlayerRatingHistory(%q, %q) want *ValidationError, got %v", args[0], args[1], err)
+		}
+	}
+}
+
+const tournamentJSON = `{
+  "id": 42,
+  "name": "Weekly 9x9",
+  "description": "Swiss, 5 rounds",
+  "rules": "japanese",
+  "time_control_parameters": {"system": "byoyomi", "main_time": 300, "period_time": 30, "periods": 3},
+  "time_start": "2024-05-01T10:00:00Z",
+  "ended": null,
+  "player_count": 16
+}`
+
+func TestClient_Tournaments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/tournaments", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 120, "next": null, "previous": null, "results": [` + tournamentJSON + `]}`))
+	})
+	mux.HandleFunc("/api/v1/tournaments/42", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(tournamentJSON))
+	})
+	c := newTestClient(t, mux)
+
+	list, total, err := c.Tournaments(1, 10)
+	if err != nil || total != 120 || len(list) != 1 {
+		t.Fatalf("Tournaments() want 1 of 120 tournaments, got %d of %d, %v", len(list), total, err)
+	}
+	got, err := c.TournamentByID(42)
+	if err != nil {
+		t.Fatalf("TournamentByID() want no error, got %v", err)
+	}
+	if got.Name != "Weekly 9x9" || got.GameRules != "japanese" || got.TimeControl.System != ClockByoyomi ||
+		got.StartTime.Unix() != 1714557600 || !got.EndTime.IsZero() || got.Participants != 16 {
+		t.Errorf("TournamentByID() got unexpected tournament %+v", got)
+	}
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	for _, tc := range []struct {
+		err              error
+		wantUnauthorized bool
+		wantRateLimited  bool
+	}{
+		{newAPIError(http.StatusUnauthorized, "url", nil), true, false},
+		{fmt.Errorf("wrapped: %w", newAPIError(http.StatusTooManyRequests, "url", nil)), false, true},
+		{newAPIError(http.StatusForbidden, "url", nil), false, false},
+		{errors.New("network error"), false, false},
+		{nil, false, false},
+	} {
+		if got := IsUnauthorized(tc.err); got != tc.wantUnauthorized {
+			t.Errorf("IsUnauthorized(%v) want %v, got %v", tc.err, tc.wantUnauthorized, got)
+		}
+		if got := IsRateLimited(tc.err); got != tc.wantRateLimited {
+			t.Errorf("IsRateLimited(%v) want %v, got %v", tc.err, tc.wantRateLimited, got)
+		}
+	}
+}
+
+func TestClient_TournamentGames(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/tournaments/42/games", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("round") != "3" || q.Get("page") != "1" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 8, "next": null, "previous": null, "results": [{
+		  "id": 2001, "name": "Weekly 9x9 Round 3", "width": 9, "height": 9, "ended": null,
+		  "tournament": 42, "komi": "5.50",
+		  "players": {"black": {"id": 7, "username": "alice"}, "white": {"id": 8, "username": "bob"}}
+		}]}`))
+	})
+	c := newTestClient(t, mux)
+
+	got, total, err := c.TournamentGames(42, 1, 10, 3)
+	if err != nil || total != 8 || len(got) != 1 {
+		t.Fatalf("TournamentGames() want 1 of 8 games, got %+v of %d, %v", got, total, err)
+	}
+	if g := got[0]; g.ID != 2001 || g.TournamentID != 42 || g.Phase != PlayPhase || g.White.Username != "bob" {
+		t.Errorf("TournamentGames() got unexpected game %+v", g)
+	}
+	var verr *ValidationError
+	if _, _, err := c.TournamentGames(42, 1, 10, -1); !errors.As(err, &verr) {
+		t.Errorf("TournamentGames() with round -1 want *ValidationError, got %v", err)
+	}
+}
+
+func TestClient_Ladders(t *testing.T) {
+	const ladder = `{"id": 4, "name": "Site 19x19 Ladder", "group": null, "board_size": 19, "player_count": 1234}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/ladders", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 3, "next": null, "previous": null, "results": [` + ladder + `]}`))
+	})
+	mux.HandleFunc("/api/v1/ladders/4", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(ladder))
+	})
+	c := newTestClient(t, mux)
+
+	list, total, err := c.Ladders(1, 10)
+	if err != nil || total != 3 || len(list) != 1 {
+		t.Fatalf("Ladders() want 1 of 3 ladders, got %d of %d, %v", len(list), total, err)
+	}
+	got, err := c.LadderByID(4)
+	if err != nil {
+		t.Fatalf("LadderByID() want no error, got %v", err)
+	}
+	if want := (Ladder{ID: 4, Name: "Site 19x19 Ladder", Size: 19, Players: 1234}); *got != want {
+		t.Errorf("LadderByID() want %+v, got %+v", want, got)
+	}
+}
+
+func TestClient_LadderPlayers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/ladders/4/players", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("player_id") == "42" {
+			w.Write([]byte(`{"count": 0, "results": []}`))
+			return
+		}
+		w.Write([]byte(`{"count": 1234, "results": [{"id": 9, "rank": 1,
+			"player": {"id": 7, "username": "alice", "ranking": 31.5},
+			"incoming_challenges": [{"id": 1}, {"id": 2}], "outgoing_challenges": [{"id": 3}]}]}`))
+	})
+	c := newTestClient(t, mux)
+	c.UserID = 42
+
+	players, total, err := c.LadderPlayers(4, 1, 10)
+	if err != nil || total != 1234 || len(players) != 1 {
+		t.Fatalf("LadderPlayers() want 1 of 1234 players, got %d of %d, %v", len(players), total, err)
+	}
+	want := LadderPlayer{UserID: 7, Username: "alice", Ranking: 31.5, IncomingChallengeCount: 2, OutgoingChallengeCount: 1, LadderPosition: 1}
+	if players[0] != want {
+		t.Errorf("LadderPlayers() want %+v, got %+v", want, players[0])
+	}
+
+	_, err = c.MyLadderPosition(4)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("MyLadderPosition() want 404 APIError, got %v", err)
+	}
+}
+
+func TestClient_Groups(t *testing.T) {
+	const group = `{"id": 12, "name": "Go Club", "member_count": 2, "website": "https://example.com"}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/groups", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 5, "results": [` + group + `]}`))
+	})
+	mux.HandleFunc("/api/v1/groups/12", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(group))
+	})
+	mux.HandleFunc("/api/v1/groups/12/members", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 2, "results": [
+			{"user": {"id": 7, "username": "alice", "ranking": 25.3}, "joined": "2024-05-01T10:00:00Z"}]}`))
+	})
+	c := newTestClient(t, mux)
+
+	groups, total, err := c.Groups(1, 10)
+	if err != nil || total != 5 || len(groups) != 1 {
+		t.Fatalf("Groups() want 1 of 5 groups, got %d of %d, %v", len(groups), total, err)
+	}
+	got, err := c.GroupByID(12)
+	if err != nil {
+		t.Fatalf("GroupByID() want no error, got %v", err)
+	}
+	if want := (Group{ID: 12, Name: "Go Club", MemberCount: 2, Website: "https://example.com"}); *got != want {
+		t.Errorf("GroupByID() want %+v, got %+v", want, got)
+	}
+
+	members, total, err := c.GroupMembers(12, 1, 10)
+	if err != nil || total != 2 || len(members) != 1 {
+		t.Fatalf("GroupMembers() want 1 of 2 members, got %d of %d, %v", len(members), total, err)
+	}
+	if m := members[0]; m.ID != 7 || m.Username != "alice" || m.Rank != 25.3 || m.JoinedAt.Year() != 2024 {
+		t.Errorf("GroupMembers() got unexpected member %+v", m)
+	}
+}
+
+func TestClient_CreateChallenge(t *testing.T) {
+	var withdrawn bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/challenges/321", func(w http.ResponseWriter, r *http.Request) {
+		withdrawn = r.Method == "DELETE"
+	})
+	challenge := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected request %s with Authorization %q", r.Method, r.Header.Get("Authorization"))
+		}
+		var got map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		game := got["game"].(map[string]any)
+		params := game["time_control_parameters"].(map[string]any)
+		if got["challenger_color"] != "black" || game["width"] != 9.0 || game["komi_auto"] != "automatic" || game["rules"] != "japanese" ||
+			game["time_control"] != "byoyomi" || params["time_control"] != "byoyomi" || params["main_time"] != 600.0 {
+			t.Errorf("unexpected challenge payload %v", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok", "challenge": 321, "game": 654}`))
+	}
+	mux.HandleFunc("/api/v1/challenges", challenge)
+	mux.HandleFunc("/api/v1/players/8/challenge", challenge)
+	c := newTestClient(t, mux)
+
+	req := &ChallengeRequest{
+		Size:        9,
+		Color:       "black",
+		TimeControl: TimeControl{System: ClockByoyomi, MainTime: 600, PeriodTime: 30, Periods: 5},
+	}
+	got, err := c.CreateChallenge(req)
+	if err != nil {
+		t.Fatalf("CreateChallenge() want no error, got %v", err)
+	}
+	if want := (ChallengeResponse{ChallengeID: 321, GameID: 654}); *got != want {
+		t.Errorf("CreateChallenge() want %+v, got %+v", want, got)
+	}
+
+	req.PlayerID = 8
+	if _, err := c.CreateChallenge(req); err != nil {
+		t.Errorf("CreateChallenge() to player want no error, got %v", err)
+	}
+	if err := c.WithdrawChallenge(321); err != nil || !withdrawn {
+		t.Errorf("WithdrawChallenge() want challenge deleted, got %v, %v", withdrawn, err)
+	}
+
+	req.Color = "purple"
+	var verr *ValidationError
+	if _, err := c.CreateChallenge(req); !errors.As(err, &verr) || verr.Field != "color" {
+		t.Errorf("CreateChallenge() with unknown color want ValidationError, got %v", err)
+	}
+}
+
+func TestClient_Challenges(t *testing.T) {
+	var rejected bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me/challenges", func(w http.ResponseWriter, r *http.Request) {
+		const sent = `{"id": 54, "challenger": {"id": 7, "username": "alice"}, "challenged": {"id": 9, "username": "carol"}, "game": {"id": 98}}`
+		const received = `{"id": 55, "challenger_color": "white",
+			"challenger": {"id": 8, "username": "bob", "ranking": 31.0},
+			"challenged": {"id": 7, "username": "alice", "ranking": 25.3},
+			"game": {"id": 99, "name": "Friendly", "width": 19, "height": 19, "ranked": true, "rules": "japanese",
+				"time_control_parameters": {"system": "fischer", "initial_time": 600, "time_increment": 10, "max_time": 1200}}}`
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("direction") == "received" {
+			w.Write([]byte(`{"count": 1, "results": [` + received + `]}`))
+			return
+		}
+		w.Write([]byte(`{"count": 2, "results": [` + sent + `, ` + received + `]}`))
+	})
+	mux.HandleFunc("/api/v1/me/challenges/55/accept", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"game": 99}`))
+	})
+	mux.HandleFunc("/api/v1/games/99", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"gamedata": {"game_id": 99, "game_name": "Friendly", "width": 19, "height": 19}}`))
+	})
+	mux.HandleFunc("/api/v1/me/challenges/56", func(w http.ResponseWriter, r *http.Request) {
+		rejected = r.Method == "DELETE"
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := newTestClient(t, mux)
+
+	for _, tc := range []struct {
+		name string
+		list func() ([]Challenge, error)
+		want []int64
+	}{
+		{"ListChallenges", c.ListChallenges, []int64{54, 55}},
+		{"IncomingChallenges", c.IncomingChallenges, []int64{55}},
+	} {
+		challenges, err := tc.list()
+		if err != nil {
+			t.Fatalf("%s() want no error, got %v", tc.name, err)
+		}
+		var ids []int64
+		for _, ch := range challenges {
+			ids = append(ids, ch.ID)
+		}
+		if !slices.Equal(ids, tc.want) {
+			t.Errorf("%s() want challenges %v, got %v", tc.name, tc.want, ids)
+		}
+		ch := challenges[len(challenges)-1]
+		if ch.Challenger.Username != "bob" || ch.GameID != 99 || ch.Color != "white" || ch.TimeControl.System != ClockFischer {
+			t.Errorf("%s() got unexpected challenge %+v", tc.name, ch)
+		}
+	}
+
+	game, err := c.AcceptChallenge(55)
+	if err != nil || game.GameID != 99 {
+		t.Errorf("AcceptChallenge() want game 99, got %+v, %v", game, err)
+	}
+	if err := c.RejectChallenge(56); err != nil || !rejected {
+		t.Errorf("RejectChallenge() want challenge deleted, got %v, %v", rejected, err)
+	}
+}
+
+func TestClient_Friends(t *testing.T) {
+	var added, removed []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me/friends", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST", "DELETE":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method == "POST" {
+				added = append(added, string(body))
+			} else {
+				removed = append(removed, string(body))
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte(`{"count": 2, "next": "page2", "results": [{"id": 7, "username": "alice"}]}`))
+			return
+		}
+		w.Write([]byte(`{"count": 2, "next": null, "results": [{"id": 8, "username": "bob"}]}`))
+	})
+	c := newTestClient(t, mux)
+
+	friends, err := c.Friends()
+	if err != nil || len(friends) != 2 || friends[1].Username != "bob" {
+		t.Errorf("Friends() want friends across pages, got %+v, %v", friends, err)
+	}
+	if err := c.AddFriend(9); err != nil || !slices.Equal(added, []string{`{"player_id":9}`}) {
+		t.Errorf("AddFriend() want player_id posted, got %v, %v", added, err)
+	}
+	if err := c.RemoveFriend(9); err != nil || !slices.Equal(removed, []string{`{"player_id":9}`}) {
+		t.Errorf("RemoveFriend() want player_id deleted, got %v, %v", removed, err)
+	}
+}
+
+func TestClient_Notifications(t *testing.T) {
+	var marked string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/notifications", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": "n1", "type": "gameStarted", "game_id": 99, "user": {"id": 8, "username": "bob"}, "timestamp": 1672531200},
+			{"id": "n2", "type": "friendRequest", "read": true, "user": {"id": 9, "username": "carol"}}]`))
+	})
+	mux.HandleFunc("/api/v1/notifications/n1", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		marked = r.Method + " " + string(body)
+	})
+	c := newTestClient(t, mux)
+
+	got, err := c.Notifications(false)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Notifications() want 2 notifications, got %+v, %v", got, err)
+	}
+	if n := got[0]; n.ID != "n1" || n.Type != NotificationGameStarted || n.GameID != 99 || n.Player.Username != "bob" {
+		t.Errorf("Notifications() got unexpected notification %+v", n)
+	}
+	var data struct{ User struct{ Username string } }
+	if err := json.Unmarshal(got[1].Data, &data); err != nil || data.User.Username != "carol" {
+		t.Errorf("Notification.Data want raw notification, got %s, %v", got[1].Data, err)
+	}
+
+	unread, err := c.Notifications(true)
+	if err != nil || len(unread) != 1 || unread[0].ID != "n1" {
+		t.Errorf("Notifications(unreadOnly) want n1 only, got %+v, %v", unread, err)
+	}
+	if err := c.MarkNotificationRead("n1"); err != nil || marked != `PATCH {"read":true}` {
+		t.Errorf("MarkNotificationRead() want read patched, got %q, %v", marked, err)
+	}
+}
+
+func TestClient_BlockUser(t *testing.T) {
+	blocked := map[string]bool{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me/blocks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 1, "results": [{"id": 66, "username": "spammer"}]}`))
+	})
+	mux.HandleFunc("/api/v1/players/66/", func(w http.ResponseWriter, r *http.Request) {
+		action := strings.TrimPrefix(r.URL.Path, "/api/v1/players/66/")
+		if blocked[action] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		blocked[action] = true
+	})
+	c := newTestClient(t, mux)
+
+	users, err := c.BlockedUsers()
+	if err != nil || len(users) != 1 || users[0].Username != "spammer" {
+		t.Errorf("BlockedUsers() want 1 blocked user, got %+v, %v", users, err)
+	}
+	if err := c.BlockUser(66); err != nil {
+		t.Errorf("BlockUser() want no error, got %v", err)
+	}
+	if err := c.BlockUser(66); !IsConflict(err) {
+		t.Errorf("BlockUser() again want conflict, got %v", err)
+	}
+	if err := c.UnblockUser(66); err != nil || !blocked["unblock"] {
+		t.Errorf("UnblockUser() want unblock posted, got %v, %v", blocked, err)
+	}
+}
+
+func TestClient_ReportPlayer(t *testing.T) {
+	var got map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/report", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.ReportPlayer(8, 99, ReportStalling, "no moves for 20 minutes"); err != nil {
+		t.Fatalf("ReportPlayer() want no error, got %v", err)
+	}
+	if got["reported_user_id"] != 8.0 || got["reported_game"] != 99.0 || got["report_type"] != "stalling" {
+		t.Errorf("ReportPlayer() got unexpected payload %v", got)
+	}
+
+	var verr *ValidationError
+	if err := c.ReportPlayer(8, 99, "rude", ""); !errors.As(err, &verr) || verr.Field != "reason" {
+		t.Errorf("ReportPlayer() with unknown reason want ValidationError, got %v", err)
+	}
+}
+
+func TestClient_UpdateProfile(t *testing.T) {
+	var got string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = r.Method + " " + string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7, "username": "alice", "about": "I am a bot", "country": "us"}`))
+	})
+	c := newTestClient(t, mux)
+
+	about := "I am a bot"
+	user, err := c.UpdateProfile(UpdateProfileParams{About: &about})
+	if err != nil || user.About != about {
+		t.Fatalf("UpdateProfile() want updated user, got %+v, %v", user, err)
+	}
+	if want := `PATCH {"about":"I am a bot"}`; got != want {
+		t.Errorf("UpdateProfile() want request %q, got %q", want, got)
+	}
+}
+
+func TestClient_Leaderboards(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/leaderboards", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("size") != "19" || q.Get("speed") != "live" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count": 500, "results": [{"rank": 1, "player_id": 7, "username": "alice", "rating": 2900.5, "deviation": 60.2}]}`))
+	})
+	c := newTestClient(t, mux)
+
+	entries, total, err := c.Leaderboards(19, "live", 1, 10)
+	if err != nil || total != 500 || len(entries) != 1 {
+		t.Fatalf("Leaderboards() want 1 of 500 entries, got %d of %d, %v", len(entries), total, err)
+	}
+	if want := (LeaderboardEntry{Rank: 1, PlayerID: 7, Username: "alice", Rating: 2900.5, Deviation: 60.2}); entries[0] != want {
+		t.Errorf("Leaderboards() want %+v, got %+v", want, entries[0])
+	}
+
+	for _, tc := range []struct {
+		size  int
+		speed string
+		field string
+	}{
+		{7, "live", "size"},
+		{19, "rapid", "speed"},
+	} {
+		var verr *ValidationError
+		if _, _, err := c.Leaderboards(tc.size, tc.speed, 1, 10); !errors.As(err, &verr) || verr.Field != tc.field {
+			t.Errorf("Leaderboards(%d, %q) want ValidationError of %s, got %v", tc.size, tc.speed, tc.field, err)
+		}
+	}
+}
+
+func TestClient_Announcements(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/announcements", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id": 3, "type": "system", "title": "Maintenance", "text": "Down for 10 minutes", "expiration": "2024-05-01T10:00:00Z"}]`))
+	})
+	c := newTestClient(t, mux)
+
+	got, err := c.Announcements()
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Announcements() want 1 announcement, got %+v, %v", got, err)
+	}
+	if a := got[0]; a.ID != 3 || a.Type != "system" || a.Title != "Maintenance" || a.Body != "Down for 10 minutes" || a.ExpiresAt.Year() != 2024 {
+		t.Errorf("Announcements() got unexpected announcement %+v", a)
+	}
+}
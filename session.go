@@ -0,0 +1,203 @@
+package googs
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// openGameTimeout is how long OpenGame waits for the initial gamedata.
+const openGameTimeout = 10 * time.Second
+
+// GameSession tracks the state of a connected game from realtime events, so
+// callers don't need to wire gamedata, moves and clock events themselves. It's
+// created by OpenGame and safe for concurrent use.
+type GameSession struct {
+	GameID int64
+
+	c        *Client
+	mu       sync.Mutex
+	game     *Game
+	board    *Board // Replayed from game.Moves, with ko history
+	state    *GameState
+	subs     []*Subscription
+	onUpdate []func()
+}
+
+// OpenGame connects to the game and returns a session which keeps the game
+// state up to date. The state is replayed from the gamedata sent by OGS,
+// then advanced by every move, clock and phase event. Close should be called
+// when done.
+func (c *Client) OpenGame(gameID int64) (*GameSession, error) {
+	s := &GameSession{GameID: gameID, c: c}
+	for event, fn := range map[string]any{
+		"gamedata": func(_ any, g *Game) { s.reset(g) },
+		"move":     func(_ any, m *GameMove) { s.move(m) },
+		"clock":    func(_ any, clock *Clock) { s.clock(clock) },
+		"phase":    func(_ any, p GamePhase) { s.phase(p) },
+	} {
+		sub, _, err := c.subscribe(fmt.Sprintf("game/%d/%s", gameID, event), fn)
+		if err != nil {
+			s.cancel()
+			return nil, err
+		}
+		s.subs = append(s.subs, sub)
+	}
+	if err := c.GameConnectAck(gameID, openGameTimeout); err != nil {
+		s.cancel()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close stops tracking the game and disconnects from it, handlers registered
+// via On... functions of the Client are kept.
+func (s *GameSession) Close() error {
+	s.cancel()
+	return s.c.GameDisconnect(s.GameID)
+}
+
+func (s *GameSession) cancel() {
+	for _, sub := range s.subs {
+		sub.Cancel()
+	}
+}
+
+// OnUpdate registers fn called after every change of the state, from the
+// goroutine delivering the event.
+func (s *GameSession) OnUpdate(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onUpdate = append(s.onUpdate, fn)
+}
+
+// Game returns the latest gamedata, with moves and clock received after it.
+func (s *GameSession) Game() *Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := *s.game
+	g.Moves = slices.Clone(g.Moves)
+	return &g
+}
+
+// State returns a copy of the current game state.
+func (s *GameSession) State() *GameState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := *s.state
+	state.Board = s.board.copyStones()
+	return &state
+}
+
+// Board returns a copy of the current board, indexed by [y][x] with value
+// 0=Empty, 1=Black, 2=White.
+func (s *GameSession) Board() [][]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.board.copyStones()
+}
+
+// Clock returns the latest clock.
+func (s *GameSession) Clock() *Clock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clock := s.game.Clock
+	return &clock
+}
+
+// Phase returns the current game phase.
+func (s *GameSession) Phase() GamePhase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Phase
+}
+
+// reset rebuilds the state from gamedata, which OGS also resends after an
+// undo.
+func (s *GameSession) reset(g *Game) {
+	g.baseURL = s.c.baseURL
+	g.Clock.Drift = s.c.clockDrift()
+	board, err := ReplayGame(g)
+	if err != nil {
+		s.warn("Failed to replay game", err)
+		board = NewGameBoard(g)
+	}
+	state := &GameState{
+		Phase:        g.Phase,
+		MoveNumber:   len(g.Moves),
+		LastMove:     OriginCoordinate{X: -1, Y: -1},
+		PlayerToMove: g.Clock.CurrentPlayerID,
+		Outcome:      g.Outcome,
+		Board:        board.Stones,
+	}
+	if n := len(g.Moves); n > 0 {
+		state.LastMove = g.Moves[n-1].OriginCoordinate
+	}
+
+	s.mu.Lock()
+	s.game, s.board, s.state = g, board, state
+	s.mu.Unlock()
+	s.updated()
+}
+
+func (s *GameSession) move(m *GameMove) {
+	s.mu.Lock()
+	if s.game == nil || m.MoveNumber != s.state.MoveNumber+1 {
+		s.mu.Unlock()
+		return // Out of sync, OGS resends gamedata
+	}
+	color := s.game.moveColor(s.state.MoveNumber)
+	if _, err := s.board.ApplyMove(color, m.Move.OriginCoordinate); err != nil {
+		s.mu.Unlock()
+		s.warn("Failed to apply move", err)
+		return
+	}
+	s.game.Moves = append(s.game.Moves, m.Move)
+	s.state.Board = s.board.Stones
+	s.state.MoveNumber = m.MoveNumber
+	s.state.LastMove = m.Move.OriginCoordinate
+	s.state.PlayerToMove = cond(color == PlayerBlack, s.game.WhitePlayerID, s.game.BlackPlayerID)
+	s.mu.Unlock()
+	s.updated()
+}
+
+func (s *GameSession) clock(clock *Clock) {
+	s.mu.Lock()
+	if s.game == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.c.syncClock(clock)
+	s.game.Clock = *clock
+	if clock.CurrentPlayerID != 0 {
+		s.state.PlayerToMove = clock.CurrentPlayerID
+	}
+	s.mu.Unlock()
+	s.updated()
+}
+
+func (s *GameSession) phase(p GamePhase) {
+	s.mu.Lock()
+	if s.game == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.game.Phase = p
+	s.state.Phase = p
+	s.mu.Unlock()
+	s.updated()
+}
+
+func (s *GameSession) updated() {
+	s.mu.Lock()
+	fns := append([]func(){}, s.onUpdate...)
+	s.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func (s *GameSession) warn(msg string, err error) {
+	s.c.warn(msg, "game", s.GameID, "error", err)
+}
@@ -0,0 +1,64 @@
+package googs
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClient_OpenGame(t *testing.T) {
+	sock := &fakeSocket{}
+	c := &Client{socket: sock}
+
+	go func() {
+		for !slices.Contains(sock.events(), "game/connect") {
+			time.Sleep(time.Millisecond)
+		}
+		sock.receive("game/123/gamedata", `{"game_id": 123, "width": 9, "height": 9, "phase": "play",
+			"black_player_id": 1, "white_player_id": 2, "clock": {"current_player": 2},
+			"moves": [[2, 2, 1000]]}`)
+	}()
+	s, err := c.OpenGame(123)
+	if err != nil {
+		t.Fatalf("OpenGame() want no error, got %v", err)
+	}
+	var updates int
+	s.OnUpdate(func() { updates++ })
+
+	state := s.State()
+	if state.MoveNumber != 1 || state.PlayerToMove != 2 || state.LastMove != (OriginCoordinate{X: 2, Y: 2}) || s.Board()[2][2] != 1 {
+		t.Errorf("want state replayed from gamedata, got %+v", state)
+	}
+
+	sock.receive("game/123/move", `{"game_id": 123, "move_number": 2, "move": [3, 2, 1000]}`)
+	sock.receive("game/123/move", `{"game_id": 123, "move_number": 2, "move": [4, 4, 1000]}`) // Duplicate
+	if board := s.Board(); board[2][3] != 2 || board[4][4] != 0 {
+		t.Errorf("want white move applied once, got %v", board)
+	}
+	if state := s.State(); state.MoveNumber != 2 || state.PlayerToMove != 1 {
+		t.Errorf("want black to move after move 2, got %+v", state)
+	}
+
+	sock.receive("game/123/clock", `{"current_player": 1, "paused_since": 1700000000}`)
+	if !s.Clock().IsPaused() {
+		t.Errorf("want clock updated, got %+v", s.Clock())
+	}
+	sock.receive("game/123/phase", `"finished"`)
+	if s.Phase() != FinishedPhase || s.Game().Phase != FinishedPhase {
+		t.Errorf("want phase %q, got %q", FinishedPhase, s.Phase())
+	}
+	if updates != 3 {
+		t.Errorf("want 3 updates, got %d", updates)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() want no error, got %v", err)
+	}
+	if got := sock.lastEmit().event; got != "game/disconnect" {
+		t.Errorf("Close() want game/disconnect, got %q", got)
+	}
+	sock.receive("game/123/phase", `"play"`)
+	if s.Phase() != FinishedPhase || updates != 3 {
+		t.Error("want no update after Close()")
+	}
+}
@@ -0,0 +1,275 @@
+package googs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ToSGF exports the game as an SGF (FF[4]) string. Handicap stones (the
+// first Handicap moves played by Black) are emitted as AB setup properties,
+// passes are emitted as empty B[] or W[] nodes.
+func (g *Game) ToSGF() (string, error) {
+	if g.Width <= 0 || g.Height <= 0 || g.Width > 52 || g.Height > 52 {
+		return "", fmt.Errorf("invalid Board dimension %d x %d", g.Width, g.Height)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(;FF[4]GM[1]CA[UTF-8]AP[googs]")
+	if g.Width == g.Height {
+		fmt.Fprintf(&sb, "SZ[%d]", g.Width)
+	} else {
+		fmt.Fprintf(&sb, "SZ[%d:%d]", g.Width, g.Height)
+	}
+	sb.WriteString("KM[" + strconv.FormatFloat(float64(g.Komi), 'f', -1, 32) + "]")
+	if g.Handicap > 0 {
+		fmt.Fprintf(&sb, "HA[%d]", g.Handicap)
+	}
+	writeSGFProp(&sb, "RU", string(g.Rules))
+	writeSGFProp(&sb, "GN", g.GameName)
+	writeSGFProp(&sb, "PB", g.Players.Black.Username)
+	writeSGFProp(&sb, "BR", rankingIfKnown(g.Players.Black))
+	writeSGFProp(&sb, "PW", g.Players.White.Username)
+	writeSGFProp(&sb, "WR", rankingIfKnown(g.Players.White))
+	if !g.StartTime.IsZero() {
+		writeSGFProp(&sb, "DT", g.StartTime.Format("2006-01-02"))
+	}
+	writeSGFProp(&sb, "RE", g.sgfResult())
+	writeSGFProp(&sb, "GC", g.Result())
+
+	start := 0
+	if g.Handicap > 1 && len(g.Moves) >= g.Handicap {
+		sb.WriteString("AB")
+		for _, m := range g.Moves[:g.Handicap] {
+			if m.IsPass() {
+				return "", fmt.Errorf("unexpected pass as handicap stone")
+			}
+			if err := g.checkBounds(m.OriginCoordinate); err != nil {
+				return "", err
+			}
+			sb.WriteString("[" + m.ToSGF() + "]")
+		}
+		start = g.Handicap
+	}
+
+	for i := start; i < len(g.Moves); i++ {
+		m := g.Moves[i]
+		vertex := "" // Pass
+		if !m.IsPass() {
+			if err := g.checkBounds(m.OriginCoordinate); err != nil {
+				return "", err
+			}
+			vertex = m.ToSGF()
+		}
+		color := cond(g.moveColor(i) == PlayerBlack, "B", "W")
+		sb.WriteString(";" + color + "[" + vertex + "]")
+	}
+	sb.WriteString(")")
+	return sb.String(), nil
+}
+
+// checkBounds returns an error when c is not on the board, which may be
+// rectangular unlike boards handled by ToSGF and ParseSGFVertex.
+func (g *Game) checkBounds(c OriginCoordinate) error {
+	if c.X < 0 || c.X >= g.Width || c.Y < 0 || c.Y >= g.Height {
+		return fmt.Errorf("move %s is out of board bounds %d x %d", c, g.Width, g.Height)
+	}
+	return nil
+}
+
+// sgfResult returns the game result in SGF notation, e.g. "B+R", "W+2.5".
+func (g *Game) sgfResult() string {
+	if g.Phase != FinishedPhase {
+		return ""
+	}
+	winner := cond(g.WinnerID == g.BlackPlayerID, "B", "W")
+	switch {
+	case g.Outcome == "Resignation":
+		return winner + "+R"
+	case g.Outcome == "Timeout":
+		return winner + "+T"
+	case strings.HasSuffix(g.Outcome, " points"):
+		return winner + "+" + strings.TrimSuffix(g.Outcome, " points")
+	}
+	return winner + "+"
+}
+
+func rankingIfKnown(p Player) string {
+	if r := p.Ranking(); r != "?" {
+		return r
+	}
+	return ""
+}
+
+// writeSGFProp writes a property with escaped text value, empty values are
+// skipped.
+func writeSGFProp(sb *strings.Builder, ident, value string) {
+	if value == "" {
+		return
+	}
+	value = strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(value)
+	sb.WriteString(ident + "[" + value + "]")
+}
+
+// ParseSGF reads a single game record in SGF format into a Game, including
+// board size, komi, handicap, rules, player names and the main line of moves.
+// Black setup stones (AB) of the root node are prepended to Moves as handicap
+// stones, the same way OGS records free handicap placement. Other setup
+// properties and variations are not supported and rejected with an error.
+func ParseSGF(data []byte) (*Game, error) {
+	nodes, err := parseSGFNodes(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Game{Width: 19, Height: 19, InitialPlayer: "black"}
+	root := nodes[0]
+	if v, ok := root["SZ"]; ok {
+		w, h, found := strings.Cut(v[0], ":")
+		if !found {
+			h = w
+		}
+		if g.Width, err = strconv.Atoi(w); err != nil {
+			return nil, fmt.Errorf("invalid SZ[%s]: %w", v[0], err)
+		}
+		if g.Height, err = strconv.Atoi(h); err != nil {
+			return nil, fmt.Errorf("invalid SZ[%s]: %w", v[0], err)
+		}
+		if g.Width <= 0 || g.Height <= 0 || g.Width > 52 || g.Height > 52 {
+			return nil, fmt.Errorf("invalid Board dimension %d x %d", g.Width, g.Height)
+		}
+	}
+	if v, ok := root["KM"]; ok {
+		komi, err := strconv.ParseFloat(v[0], 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KM[%s]: %w", v[0], err)
+		}
+		g.Komi = float32(komi)
+	}
+	if v, ok := root["HA"]; ok {
+		if g.Handicap, err = strconv.Atoi(v[0]); err != nil {
+			return nil, fmt.Errorf("invalid HA[%s]: %w", v[0], err)
+		}
+	}
+	g.Rules = RuleSet(root.first("RU"))
+	g.GameName = root.first("GN")
+	g.Players.Black.Username = root.first("PB")
+	g.Players.White.Username = root.first("PW")
+
+	// ParseSGFVertex checks the longer side of a rectangular board
+	parse := func(v string) (*OriginCoordinate, error) {
+		c, err := ParseSGFVertex(v, cond(g.Width > g.Height, g.Width, g.Height))
+		if err == nil && !c.IsPass() {
+			err = g.checkBounds(*c)
+		}
+		return c, err
+	}
+	for _, v := range root["AB"] {
+		c, err := parse(v)
+		if err != nil || c.IsPass() {
+			return nil, fmt.Errorf("invalid AB[%s]", v)
+		}
+		g.Moves = append(g.Moves, Move{OriginCoordinate: *c})
+	}
+	if len(root["AB"]) > 0 {
+		g.Handicap = len(root["AB"])
+	}
+
+	last := cond(len(root["AB"]) > 0, "B", "")
+	for i, node := range nodes {
+		// Only handicap stones (AB in the root node) are kept as moves
+		if node["AW"] != nil || node["AE"] != nil || (i > 0 && node["AB"] != nil) {
+			return nil, fmt.Errorf("setup stones in node %d are not supported", i)
+		}
+		color, values := "B", node["B"]
+		if values == nil {
+			color, values = "W", node["W"]
+		}
+		if values == nil {
+			continue
+		}
+		if last == "" {
+			g.InitialPlayer = cond(color == "B", "black", "white")
+		}
+		if color == last {
+			return nil, fmt.Errorf("consecutive moves by the same color in node %d are not supported", i)
+		}
+		last = color
+
+		c, err := parse(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid move %s[%s] in node %d: %w", color, values[0], i, err)
+		}
+		g.Moves = append(g.Moves, Move{OriginCoordinate: *c})
+	}
+	return g, nil
+}
+
+// sgfNode maps property identifiers to their values.
+type sgfNode map[string][]string
+
+func (n sgfNode) first(ident string) string {
+	if v := n[ident]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// parseSGFNodes parses the sequence of nodes of a game tree without
+// variations.
+func parseSGFNodes(s string) ([]sgfNode, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") {
+		return nil, fmt.Errorf("invalid SGF: expected '(' at the beginning")
+	}
+
+	var nodes []sgfNode
+	var ident string
+	var afterValue bool
+	for i := 1; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == ';':
+			nodes = append(nodes, sgfNode{})
+			ident, afterValue = "", false
+		case ch >= 'A' && ch <= 'Z':
+			if len(nodes) == 0 {
+				return nil, fmt.Errorf("invalid SGF: property outside of node at offset %d", i)
+			}
+			if afterValue {
+				ident, afterValue = "", false // Next property
+			}
+			ident += string(ch)
+		case ch == '[':
+			if ident == "" {
+				return nil, fmt.Errorf("invalid SGF: value without property at offset %d", i)
+			}
+			var value strings.Builder
+			for i++; i < len(s) && s[i] != ']'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("invalid SGF: unterminated value of %s", ident)
+			}
+			node := nodes[len(nodes)-1]
+			node[ident] = append(node[ident], value.String())
+			afterValue = true
+		case ch == '(':
+			return nil, fmt.Errorf("SGF variations are not supported (offset %d)", i)
+		case ch == ')':
+			if rest := strings.TrimSpace(s[i+1:]); rest != "" {
+				return nil, fmt.Errorf("multiple SGF game trees are not supported")
+			}
+			if len(nodes) == 0 {
+				return nil, fmt.Errorf("invalid SGF: empty game tree")
+			}
+			return nodes, nil
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+		default:
+			return nil, fmt.Errorf("invalid SGF: unexpected %q at offset %d", ch, i)
+		}
+	}
+	return nil, fmt.Errorf("invalid SGF: missing ')' at the end")
+}
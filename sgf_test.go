@@ -0,0 +1,143 @@
+package googs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// A small 9x9 game as received from "game/:id/gamedata".
+const gamedata9x9 = `{
+  "game_id": 123,
+  "game_name": "Friendly [match]",
+  "width": 9,
+  "height": 9,
+  "komi": 6.5,
+  "handicap": 0,
+  "rules": "japanese",
+  "initial_player": "black",
+  "phase": "finished",
+  "outcome": "Resignation",
+  "black_player_id": 1,
+  "white_player_id": 2,
+  "winner": 2,
+  "players": {
+    "black": {"id": 1, "username": "alice", "rank": 25},
+    "white": {"id": 2, "username": "bob", "rank": 31}
+  },
+  "moves": [[2, 6, 1000], [6, 2, 1000], [-1, -1, 500], [4, 4, 800]]
+}`
+
+func TestGame_ToSGF(t *testing.T) {
+	var g Game
+	if err := json.Unmarshal([]byte(gamedata9x9), &g); err != nil {
+		t.Fatal(err)
+	}
+	want := `(;FF[4]GM[1]CA[UTF-8]AP[googs]SZ[9]KM[6.5]RU[japanese]GN[Friendly [match\]]` +
+		`PB[alice]BR[5k]PW[bob]WR[2d]RE[W+R]GC[(W) bob[2d\] won by Resignation]` +
+		`;B[cg];W[gc];B[];W[ee])`
+	got, err := g.ToSGF()
+	if err != nil {
+		t.Fatalf("ToSGF() want no error, got %v", err)
+	}
+	if got != want {
+		t.Errorf("ToSGF() want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestGame_ToSGF_Handicap(t *testing.T) {
+	g := Game{
+		Width:    9,
+		Height:   9,
+		Handicap: 2,
+		Moves: []Move{
+			{OriginCoordinate: OriginCoordinate{X: 2, Y: 6}},
+			{OriginCoordinate: OriginCoordinate{X: 6, Y: 2}},
+			{OriginCoordinate: OriginCoordinate{X: 4, Y: 4}},
+		},
+	}
+	want := `(;FF[4]GM[1]CA[UTF-8]AP[googs]SZ[9]KM[0]HA[2]AB[cg][gc];W[ee])`
+	got, err := g.ToSGF()
+	if err != nil {
+		t.Fatalf("ToSGF() want no error, got %v", err)
+	}
+	if got != want {
+		t.Errorf("ToSGF() want\n%s\ngot\n%s", want, got)
+	}
+
+	g.Moves = append(g.Moves, Move{OriginCoordinate: OriginCoordinate{X: 9, Y: 0}})
+	if _, err := g.ToSGF(); err == nil {
+		t.Errorf("ToSGF() with out of bounds move want error, got nil")
+	}
+}
+
+func TestParseSGF(t *testing.T) {
+	var g Game
+	if err := json.Unmarshal([]byte(gamedata9x9), &g); err != nil {
+		t.Fatal(err)
+	}
+	sgf, err := g.ToSGF()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseSGF([]byte(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF(%q) want no error, got %v", sgf, err)
+	}
+	if got.Width != 9 || got.Height != 9 || got.Komi != 6.5 || got.Rules != "japanese" ||
+		got.GameName != g.GameName || got.InitialPlayer != "black" ||
+		got.Players.Black.Username != "alice" || got.Players.White.Username != "bob" {
+		t.Errorf("ParseSGF(%q) got unexpected game %+v", sgf, got)
+	}
+	if len(got.Moves) != len(g.Moves) {
+		t.Fatalf("ParseSGF(%q) want %d moves, got %d", sgf, len(g.Moves), len(got.Moves))
+	}
+	for i, m := range got.Moves {
+		if m.OriginCoordinate != g.Moves[i].OriginCoordinate {
+			t.Errorf("ParseSGF(%q) move %d want %s, got %s", sgf, i, g.Moves[i].OriginCoordinate, m.OriginCoordinate)
+		}
+	}
+}
+
+func TestParseSGF_Handicap(t *testing.T) {
+	sgf := "(;GM[1]FF[4]SZ[9]\nHA[2] KM[0.5]AB[cg]\n[gc];W[ee];B[tt])"
+	got, err := ParseSGF([]byte(sgf))
+	if err != nil {
+		t.Fatalf("ParseSGF(%q) want no error, got %v", sgf, err)
+	}
+	want := []OriginCoordinate{{2, 6}, {6, 2}, {4, 4}, {-1, -1}}
+	if got.Handicap != 2 || got.Komi != 0.5 || len(got.Moves) != len(want) {
+		t.Fatalf("ParseSGF(%q) got unexpected game %+v", sgf, got)
+	}
+	for i, m := range got.Moves {
+		if m.OriginCoordinate != want[i] {
+			t.Errorf("ParseSGF(%q) move %d want %s, got %s", sgf, i, want[i], m.OriginCoordinate)
+		}
+	}
+}
+
+func TestParseSGF_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		sgf  string
+	}{
+		{"empty", ""},
+		{"variation", "(;SZ[9];B[aa](;W[bb])(;W[cc]))"},
+		{"multiple game trees", "(;SZ[9];B[aa])(;SZ[9];B[bb])"},
+		{"unterminated", "(;SZ[9];B[aa"},
+		{"out of bounds", "(;SZ[9];B[jj])"},
+		{"out of bounds on rectangular board", "(;SZ[9:13];B[ka])"},
+		{"invalid point", "(;SZ[9];B[a])"},
+		{"invalid size", "(;SZ[x])"},
+		{"consecutive moves", "(;SZ[9];B[aa];B[bb])"},
+		{"setup stones in root", "(;SZ[9]AB[cc]AW[gg];W[ee])"},
+		{"removed stones in root", "(;SZ[9]AE[cc];B[ee])"},
+		{"setup stones in move node", "(;SZ[9];B[aa];AB[cc]W[ee])"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := ParseSGF([]byte(tc.sgf)); err == nil {
+				t.Errorf("ParseSGF(%q) want error, got %+v", tc.sgf, got)
+			}
+		})
+	}
+}
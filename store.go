@@ -0,0 +1,74 @@
+package googs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// credentialsVersion is the format version of persisted credentials, files
+// written before versioning was introduced have no version (0) and are read
+// the same way as version 1.
+const credentialsVersion = 1
+
+// CredentialStore persists Client credentials in JSON format, e.g. in a file,
+// a database or a Kubernetes secret.
+type CredentialStore interface {
+	Load() ([]byte, error)
+	Save([]byte) error
+}
+
+// FileStore is a CredentialStore backed by a local file readable only by the
+// owner.
+type FileStore string
+
+func (f FileStore) Load() ([]byte, error) {
+	return os.ReadFile(string(f))
+}
+
+func (f FileStore) Save(data []byte) error {
+	return os.WriteFile(string(f), data, 0600)
+}
+
+// SaveTo stores authenticated Client credentials into the store.
+func (c *Client) SaveTo(store CredentialStore) error {
+	data, err := c.marshal()
+	if err != nil {
+		return err
+	}
+	return store.Save(data)
+}
+
+// WriteTo writes authenticated Client credentials to w in the same JSON format
+// as Save(), it implements io.WriterTo.
+func (c *Client) WriteTo(w io.Writer) (int64, error) {
+	data, err := c.marshal()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+func (c *Client) marshal() ([]byte, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return json.MarshalIndent(struct {
+		Version int `json:"version"`
+		*Client
+	}{credentialsVersion, c}, "", "  ")
+}
+
+func (c *Client) unmarshal(data []byte) error {
+	var v struct {
+		Version int
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Version > credentialsVersion {
+		return fmt.Errorf("unsupported credentials version %d, want up to %d", v.Version, credentialsVersion)
+	}
+	return json.Unmarshal(data, c)
+}
@@ -0,0 +1,129 @@
+package googs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memStore is a CredentialStore in memory.
+type memStore struct {
+	data []byte
+}
+
+func (s *memStore) Load() ([]byte, error) { return s.data, nil }
+
+func (s *memStore) Save(data []byte) error {
+	s.data = data
+	return nil
+}
+
+func TestFileStore(t *testing.T) {
+	c := &Client{ClientID: "id", Token: Token{AccessToken: "token", RefreshToken: "refresh"}}
+	store := FileStore(filepath.Join(t.TempDir(), "secret.json"))
+	if err := c.SaveTo(store); err != nil {
+		t.Fatalf("SaveTo() want no error, got %v", err)
+	}
+
+	data, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() want no error, got %v", err)
+	}
+	var got Client
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ClientID != "id" || got.AccessToken != "token" || got.RefreshToken != "refresh" {
+		t.Errorf("Load() got unexpected credentials %s", data)
+	}
+}
+
+func TestClient_Get_RefreshSavedToStore(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]any{"id": 1, "username": "alice"})
+	})
+	mux.HandleFunc("/oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"access_token": "new", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("/api/v1/ui/config/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{})
+	})
+	c := newTestClient(t, mux)
+	c.RefreshToken = "old"
+	store := &memStore{}
+	c.store = store
+
+	if _, err := c.AboutMe(); err != nil {
+		t.Fatalf("AboutMe() want no error, got %v", err)
+	}
+	var got Client
+	if err := json.Unmarshal(store.data, &got); err != nil || got.AccessToken != "new" {
+		t.Errorf("refreshed credentials want saved to store, got %s, %v", store.data, err)
+	}
+}
+
+func TestClient_WriteTo(t *testing.T) {
+	c := &Client{
+		ClientID: "id",
+		Token:    Token{AccessToken: "token", RefreshToken: "refresh"},
+		Auth:     Auth{UserJWT: "jwt"},
+	}
+	var buf bytes.Buffer
+	n, err := c.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo() want %d bytes written, got %d, %v", buf.Len(), n, err)
+	}
+	if !strings.Contains(buf.String(), `"version": 1`) {
+		t.Errorf("WriteTo() want version, got %s", buf.String())
+	}
+
+	var got Client
+	if err := got.unmarshal(buf.Bytes()); err != nil {
+		t.Fatalf("unmarshal() want no error, got %v", err)
+	}
+	if got.ClientID != "id" || got.AccessToken != "token" || got.RefreshToken != "refresh" || got.UserJWT != "jwt" {
+		t.Errorf("round trip got unexpected credentials %+v %+v", got.Token, got.Auth)
+	}
+}
+
+func TestClient_unmarshal_Legacy(t *testing.T) {
+	// Written by Save() before the version field was introduced
+	legacy := `{
+  "client_id": "id",
+  "access_token": "token",
+  "refresh_token": "refresh",
+  "expires_at": "2024-01-01T00:00:00Z",
+  "chat_auth": "chat",
+  "notification_auth": "notification",
+  "user_jwt": "jwt"
+}`
+	secretFile := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(secretFile, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := FileStore(secretFile).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Client
+	if err := got.unmarshal(data); err != nil {
+		t.Fatalf("unmarshal() want no error, got %v", err)
+	}
+	if got.ClientID != "id" || got.AccessToken != "token" || got.UserJWT != "jwt" || got.ExpiresAt.Year() != 2024 {
+		t.Errorf("unmarshal() got unexpected credentials %+v %+v", got.Token, got.Auth)
+	}
+
+	if err := got.unmarshal([]byte(`{"version": 2}`)); err == nil {
+		t.Errorf("unmarshal() of future version want error, got nil")
+	}
+}